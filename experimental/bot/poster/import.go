@@ -10,3 +10,9 @@ type PostAPI interface {
 	DeletePost(postID string) error
 	SendEphemeralPost(userID string, post *model.Post)
 }
+
+// QueueAPI defines the portion of the Post Service used by the Queue
+type QueueAPI interface {
+	CreatePost(post *model.Post) error
+	DM(senderUserID, receiverUserID string, post *model.Post) error
+}
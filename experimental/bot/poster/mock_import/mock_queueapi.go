@@ -0,0 +1,62 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-api/experimental/bot/poster (interfaces: QueueAPI)
+
+// Package mock_import is a generated GoMock package.
+package mock_import
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/mattermost/mattermost-server/v5/model"
+	reflect "reflect"
+)
+
+// MockQueueAPI is a mock of QueueAPI interface
+type MockQueueAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockQueueAPIMockRecorder
+}
+
+// MockQueueAPIMockRecorder is the mock recorder for MockQueueAPI
+type MockQueueAPIMockRecorder struct {
+	mock *MockQueueAPI
+}
+
+// NewMockQueueAPI creates a new mock instance
+func NewMockQueueAPI(ctrl *gomock.Controller) *MockQueueAPI {
+	mock := &MockQueueAPI{ctrl: ctrl}
+	mock.recorder = &MockQueueAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockQueueAPI) EXPECT() *MockQueueAPIMockRecorder {
+	return m.recorder
+}
+
+// CreatePost mocks base method
+func (m *MockQueueAPI) CreatePost(arg0 *model.Post) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePost", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePost indicates an expected call of CreatePost
+func (mr *MockQueueAPIMockRecorder) CreatePost(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockQueueAPI)(nil).CreatePost), arg0)
+}
+
+// DM mocks base method
+func (m *MockQueueAPI) DM(arg0, arg1 string, arg2 *model.Post) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DM", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DM indicates an expected call of DM
+func (mr *MockQueueAPIMockRecorder) DM(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DM", reflect.TypeOf((*MockQueueAPI)(nil).DM), arg0, arg1, arg2)
+}
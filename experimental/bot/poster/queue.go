@@ -0,0 +1,457 @@
+package poster
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+)
+
+const (
+	// DefaultGlobalInterval is the minimum time between any two posts sent by the queue by default.
+	DefaultGlobalInterval = 50 * time.Millisecond
+	// DefaultChannelInterval is the minimum time between two posts sent to the same channel by default.
+	DefaultChannelInterval = time.Second
+	// DefaultMaxRetries is the number of times a post failing with a transient error is retried by default.
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the wait before the first retry of a failed post by default.
+	DefaultRetryBackoff = 500 * time.Millisecond
+	// DefaultMaxRetryBackoff is the maximum wait between retries of a failed post by default.
+	DefaultMaxRetryBackoff = 10 * time.Second
+	// DefaultFlushTimeout is the time Close waits for queued posts to be sent by default.
+	DefaultFlushTimeout = 10 * time.Second
+)
+
+// ErrQueueClosed is returned when trying to enqueue a post on a closed queue.
+var ErrQueueClosed = errors.New("queue closed")
+
+// QueueOption defines each option that can be passed in the creation of the Queue.
+// Options functions available are GlobalRateLimit, ChannelRateLimit, Retries, CoalesceWindow,
+// FlushTimeout, RetryIf and QueueLogger.
+type QueueOption func(*Queue)
+
+// GlobalRateLimit defines the minimum time between any two posts sent by the queue.
+// Defaults to 50 milliseconds.
+func GlobalRateLimit(interval time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.globalInterval = interval
+	}
+}
+
+// ChannelRateLimit defines the minimum time between two posts sent to the same channel, or
+// direct messages sent to the same user.
+// Defaults to 1 second.
+func ChannelRateLimit(interval time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.channelInterval = interval
+	}
+}
+
+// Retries defines how many times a post failing with a transient error is retried, and the
+// exponential backoff applied between attempts.
+// Defaults to 3 retries, starting at 500 milliseconds and capped at 10 seconds.
+func Retries(maxRetries int, backoff, maxBackoff time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.maxRetries = maxRetries
+		q.retryBackoff = backoff
+		q.maxRetryBackoff = maxBackoff
+	}
+}
+
+// CoalesceWindow defines the window during which plain text messages to the same recipient are
+// merged into a single post. Each message is separated by a newline.
+// Defaults to 0, which disables coalescing.
+func CoalesceWindow(window time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.coalesceWindow = window
+	}
+}
+
+// FlushTimeout defines how long Close waits for queued posts to be sent before dropping them.
+// Defaults to 10 seconds.
+func FlushTimeout(timeout time.Duration) QueueOption {
+	return func(q *Queue) {
+		q.flushTimeout = timeout
+	}
+}
+
+// RetryIf defines which errors are considered transient and are therefore retried.
+// Defaults to IsTransientError.
+func RetryIf(isTransient func(error) bool) QueueOption {
+	return func(q *Queue) {
+		q.isTransient = isTransient
+	}
+}
+
+// QueueLogger defines the logger used to report posts that could not be delivered.
+// Defaults to a logger that performs no action.
+func QueueLogger(l logger.Logger) QueueOption {
+	return func(q *Queue) {
+		q.logger = l
+	}
+}
+
+// IsTransientError returns true for errors worth retrying: server side failures and rate
+// limiting errors returned by the plugin API.
+func IsTransientError(err error) bool {
+	var appErr *model.AppError
+	if !errors.As(err, &appErr) {
+		return false
+	}
+
+	return appErr.StatusCode == 0 ||
+		appErr.StatusCode == http.StatusTooManyRequests ||
+		appErr.StatusCode >= http.StatusInternalServerError
+}
+
+type queuedPost struct {
+	key           string
+	userID        string
+	post          *model.Post
+	coalesceUntil time.Time
+	retryAt       time.Time
+	attempts      int
+}
+
+// Queue sends posts asynchronously, rate limiting them globally and per channel, retrying them
+// on transient errors and optionally coalescing messages to the same recipient.
+//
+// Queue implements DMer, so it can be used in place of a Poster wherever many messages are sent
+// in a burst, e.g. by the admin cc logger. Call Close when the plugin is deactivated to flush the
+// pending posts.
+type Queue struct {
+	postAPI         QueueAPI
+	logger          logger.Logger
+	globalInterval  time.Duration
+	channelInterval time.Duration
+	maxRetries      int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	coalesceWindow  time.Duration
+	flushTimeout    time.Duration
+	isTransient     func(error) bool
+
+	mu         sync.Mutex
+	posterID   string
+	pending    []*queuedPost
+	nextByKey  map[string]time.Time
+	nextGlobal time.Time
+	closed     bool
+	abandoned  bool
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+/*
+NewQueue creates a new Queue and starts sending posts in the background.
+
+- postAPI: The API used to send the posts.
+
+- posterID: The Mattermost User ID of the poster.
+
+- options: Optional options for the Queue. Available options are GlobalRateLimit, ChannelRateLimit, Retries, CoalesceWindow, FlushTimeout, RetryIf and QueueLogger.
+*/
+func NewQueue(postAPI QueueAPI, posterID string, options ...QueueOption) *Queue {
+	q := &Queue{
+		postAPI:         postAPI,
+		logger:          logger.NewNilLogger(),
+		globalInterval:  DefaultGlobalInterval,
+		channelInterval: DefaultChannelInterval,
+		maxRetries:      DefaultMaxRetries,
+		retryBackoff:    DefaultRetryBackoff,
+		maxRetryBackoff: DefaultMaxRetryBackoff,
+		flushTimeout:    DefaultFlushTimeout,
+		isTransient:     IsTransientError,
+		posterID:        posterID,
+		nextByKey:       map[string]time.Time{},
+		wake:            make(chan struct{}, 1),
+		done:            make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(q)
+	}
+
+	go q.run()
+
+	return q
+}
+
+// DM enqueues a simple Direct Message to the specified user.
+//
+// As the post is sent asynchronously, the returned post ID is always empty.
+func (q *Queue) DM(mattermostUserID, format string, args ...interface{}) (string, error) {
+	post := &model.Post{
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	return "", q.DMPost(mattermostUserID, post)
+}
+
+// DMPost enqueues a post to be sent as a Direct Message to the specified user. The post is copied,
+// so the caller can reuse it.
+func (q *Queue) DMPost(mattermostUserID string, post *model.Post) error {
+	return q.enqueue(&queuedPost{
+		key:    "dm_" + mattermostUserID,
+		userID: mattermostUserID,
+		post:   post,
+	})
+}
+
+// Post enqueues a post to be created in the channel given by post.ChannelId. The post is copied,
+// so the caller can reuse it.
+func (q *Queue) Post(post *model.Post) error {
+	if post.ChannelId == "" {
+		return errors.New("post has no channel")
+	}
+
+	return q.enqueue(&queuedPost{
+		key:  post.ChannelId,
+		post: post,
+	})
+}
+
+// UpdatePosterID updates the Mattermost User ID of the poster for all subsequently sent posts.
+func (q *Queue) UpdatePosterID(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.posterID = id
+}
+
+// Len returns the number of posts waiting to be sent.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+// Close stops accepting new posts and waits for the pending posts to be sent, ignoring any
+// coalesce window but still honoring the rate limits. Posts not sent within the flush timeout
+// are dropped, and an error is returned.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		<-q.done
+		return nil
+	}
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+
+	timer := time.NewTimer(q.flushTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-timer.C:
+	}
+
+	q.mu.Lock()
+	dropped := len(q.pending)
+	q.pending = nil
+	q.abandoned = true
+	q.mu.Unlock()
+	q.signal()
+
+	<-q.done
+
+	if dropped > 0 {
+		return errors.Errorf("dropped %d queued posts", dropped)
+	}
+
+	return nil
+}
+
+func (q *Queue) enqueue(item *queuedPost) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	// The post is sent later, and may be coalesced with others, so keep a copy of it to leave the
+	// caller's post untouched.
+	item.post = item.post.Clone()
+
+	if q.coalesceWindow > 0 && canCoalesce(item.post) {
+		for _, existing := range q.pending {
+			if existing.key == item.key &&
+				existing.attempts == 0 &&
+				existing.post.RootId == item.post.RootId &&
+				canCoalesce(existing.post) {
+				existing.post.Message += "\n" + item.post.Message
+				return nil
+			}
+		}
+
+		item.coalesceUntil = time.Now().Add(q.coalesceWindow)
+	}
+
+	q.pending = append(q.pending, item)
+	q.signal()
+
+	return nil
+}
+
+func canCoalesce(post *model.Post) bool {
+	return post.Type == "" && len(post.GetProps()) == 0 && len(post.FileIds) == 0
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) run() {
+	defer close(q.done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		q.mu.Lock()
+		item, wait := q.next(time.Now())
+		finished := item == nil && q.closed && len(q.pending) == 0
+		q.mu.Unlock()
+
+		if finished {
+			return
+		}
+
+		if item != nil {
+			q.send(item)
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		if wait < 0 {
+			<-q.wake
+			continue
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-q.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// next removes and returns the first pending post that can be sent now. If none can, it returns
+// how long to wait before trying again, or a negative duration if there is nothing to send.
+//
+// It must be called with the mutex held.
+func (q *Queue) next(now time.Time) (*queuedPost, time.Duration) {
+	if len(q.pending) == 0 {
+		return nil, -1
+	}
+
+	if q.nextGlobal.After(now) {
+		return nil, q.nextGlobal.Sub(now)
+	}
+
+	var earliest time.Time
+	for i, item := range q.pending {
+		readyAt := item.retryAt
+		if !q.closed && item.coalesceUntil.After(readyAt) {
+			readyAt = item.coalesceUntil
+		}
+		if next := q.nextByKey[item.key]; next.After(readyAt) {
+			readyAt = next
+		}
+
+		if !readyAt.After(now) {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.nextGlobal = now.Add(q.globalInterval)
+			q.nextByKey[item.key] = now.Add(q.channelInterval)
+			q.pruneRateLimits(now)
+
+			return item, 0
+		}
+
+		if earliest.IsZero() || readyAt.Before(earliest) {
+			earliest = readyAt
+		}
+	}
+
+	return nil, earliest.Sub(now)
+}
+
+// pruneRateLimits forgets the channels whose rate limit has elapsed.
+//
+// It must be called with the mutex held.
+func (q *Queue) pruneRateLimits(now time.Time) {
+	for key, next := range q.nextByKey {
+		if !next.After(now) {
+			delete(q.nextByKey, key)
+		}
+	}
+}
+
+func (q *Queue) send(item *queuedPost) {
+	q.mu.Lock()
+	posterID := q.posterID
+	q.mu.Unlock()
+
+	var err error
+	if item.userID != "" {
+		err = q.postAPI.DM(posterID, item.userID, item.post)
+	} else {
+		item.post.UserId = posterID
+		err = q.postAPI.CreatePost(item.post)
+	}
+
+	if err == nil {
+		return
+	}
+
+	if item.attempts < q.maxRetries && q.isTransient(err) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		// Drop the retry if the queue was abandoned while the post was being sent.
+		if q.abandoned {
+			q.logger.WithError(err).Errorf("Dropping queued post for %s after %d attempts", item.key, item.attempts+1)
+			return
+		}
+
+		item.retryAt = time.Now().Add(q.backoff(item.attempts))
+		item.attempts++
+		q.pending = append(q.pending, item)
+		return
+	}
+
+	q.logger.WithError(err).Errorf("Failed to send queued post for %s after %d attempts", item.key, item.attempts+1)
+}
+
+func (q *Queue) backoff(attempts int) time.Duration {
+	backoff := q.retryBackoff
+	for i := 0; i < attempts && backoff < q.maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > q.maxRetryBackoff {
+		return q.maxRetryBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,292 @@
+package poster
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster/mock_import"
+)
+
+func TestQueueInterface(t *testing.T) {
+	t.Run("Plugin API satisfy the interface", func(t *testing.T) {
+		api := &plugintest.API{}
+		driver := &plugintest.Driver{}
+		client := pluginapi.NewClient(api, driver)
+		queue := NewQueue(&client.Post, botID)
+		defer queue.Close()
+		var _ DMer = queue
+	})
+}
+
+func TestQueue(t *testing.T) {
+	t.Run("DM is sent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.
+			EXPECT().
+			DM(botID, userID, &model.Post{Message: "hello 1"}).
+			Return(nil).
+			Times(1)
+
+		queue := NewQueue(postAPI, botID)
+
+		postID, err := queue.DM(userID, "hello %d", 1)
+		require.NoError(t, err)
+		assert.Empty(t, postID)
+
+		require.NoError(t, queue.Close())
+		assert.Equal(t, 0, queue.Len())
+	})
+
+	t.Run("post is created in channel", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.
+			EXPECT().
+			CreatePost(&model.Post{UserId: botID, ChannelId: dmChannelID, Message: "hello"}).
+			Return(nil).
+			Times(1)
+
+		queue := NewQueue(postAPI, botID)
+
+		err := queue.Post(&model.Post{ChannelId: dmChannelID, Message: "hello"})
+		require.NoError(t, err)
+
+		require.NoError(t, queue.Close())
+	})
+
+	t.Run("post without channel is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		queue := NewQueue(mock_import.NewMockQueueAPI(ctrl), botID)
+		defer queue.Close()
+
+		err := queue.Post(&model.Post{Message: "hello"})
+		require.Error(t, err)
+	})
+
+	t.Run("closed queue rejects posts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		queue := NewQueue(mock_import.NewMockQueueAPI(ctrl), botID)
+		require.NoError(t, queue.Close())
+		require.NoError(t, queue.Close())
+
+		_, err := queue.DM(userID, "hello")
+		require.Equal(t, ErrQueueClosed, err)
+	})
+
+	t.Run("posts to the same channel are rate limited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var mu sync.Mutex
+		var sent []time.Time
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.
+			EXPECT().
+			DM(botID, userID, gomock.Any()).
+			DoAndReturn(func(string, string, *model.Post) error {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, time.Now())
+				return nil
+			}).
+			Times(3)
+
+		interval := 50 * time.Millisecond
+		queue := NewQueue(postAPI, botID, GlobalRateLimit(0), ChannelRateLimit(interval))
+		for i := 0; i < 3; i++ {
+			_, err := queue.DM(userID, "hello %d", i)
+			require.NoError(t, err)
+		}
+		require.NoError(t, queue.Close())
+
+		require.Len(t, sent, 3)
+		assert.GreaterOrEqual(t, int64(sent[1].Sub(sent[0])), int64(interval))
+		assert.GreaterOrEqual(t, int64(sent[2].Sub(sent[1])), int64(interval))
+	})
+
+	t.Run("posts are rate limited globally", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var mu sync.Mutex
+		var sent []time.Time
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.
+			EXPECT().
+			DM(botID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(string, string, *model.Post) error {
+				mu.Lock()
+				defer mu.Unlock()
+				sent = append(sent, time.Now())
+				return nil
+			}).
+			Times(2)
+
+		interval := 50 * time.Millisecond
+		queue := NewQueue(postAPI, botID, GlobalRateLimit(interval), ChannelRateLimit(0))
+		_, err := queue.DM("user1", "hello")
+		require.NoError(t, err)
+		_, err = queue.DM("user2", "hello")
+		require.NoError(t, err)
+		require.NoError(t, queue.Close())
+
+		require.Len(t, sent, 2)
+		assert.GreaterOrEqual(t, int64(sent[1].Sub(sent[0])), int64(interval))
+	})
+
+	t.Run("transient errors are retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transientErr := model.NewAppError("here", "id", nil, "an error occurred", http.StatusInternalServerError)
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		gomock.InOrder(
+			postAPI.EXPECT().DM(botID, userID, gomock.Any()).Return(transientErr).Times(2),
+			postAPI.EXPECT().DM(botID, userID, gomock.Any()).Return(nil).Times(1),
+		)
+
+		queue := NewQueue(postAPI, botID, Retries(3, time.Millisecond, 5*time.Millisecond), ChannelRateLimit(0))
+		_, err := queue.DM(userID, "hello")
+		require.NoError(t, err)
+		require.NoError(t, queue.Close())
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		transientErr := model.NewAppError("here", "id", nil, "an error occurred", http.StatusTooManyRequests)
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.EXPECT().DM(botID, userID, gomock.Any()).Return(transientErr).Times(3)
+
+		queue := NewQueue(postAPI, botID, Retries(2, time.Millisecond, 5*time.Millisecond), ChannelRateLimit(0))
+		_, err := queue.DM(userID, "hello")
+		require.NoError(t, err)
+		require.NoError(t, queue.Close())
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.EXPECT().DM(botID, userID, gomock.Any()).Return(errors.New("mock error")).Times(1)
+
+		queue := NewQueue(postAPI, botID, Retries(3, time.Millisecond, 5*time.Millisecond), ChannelRateLimit(0))
+		_, err := queue.DM(userID, "hello")
+		require.NoError(t, err)
+		require.NoError(t, queue.Close())
+	})
+
+	t.Run("messages to the same recipient are coalesced", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.
+			EXPECT().
+			DM(botID, userID, &model.Post{Message: "first\nsecond"}).
+			Return(nil).
+			Times(1)
+		postAPI.
+			EXPECT().
+			DM(botID, "another-user", &model.Post{Message: "third"}).
+			Return(nil).
+			Times(1)
+
+		queue := NewQueue(postAPI, botID, CoalesceWindow(time.Hour))
+		_, err := queue.DM(userID, "first")
+		require.NoError(t, err)
+		_, err = queue.DM("another-user", "third")
+		require.NoError(t, err)
+		_, err = queue.DM(userID, "second")
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, queue.Len())
+		require.NoError(t, queue.Close())
+	})
+
+	t.Run("coalescing leaves the caller's posts untouched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.
+			EXPECT().
+			CreatePost(&model.Post{UserId: botID, ChannelId: "channel", Message: "first\nsecond"}).
+			Return(nil).
+			Times(1)
+
+		queue := NewQueue(postAPI, botID, CoalesceWindow(time.Hour))
+		first := &model.Post{ChannelId: "channel", Message: "first"}
+		require.NoError(t, queue.Post(first))
+		require.NoError(t, queue.Post(&model.Post{ChannelId: "channel", Message: "second"}))
+
+		assert.Equal(t, "first", first.Message)
+		require.NoError(t, queue.Close())
+		assert.Equal(t, &model.Post{ChannelId: "channel", Message: "first"}, first)
+	})
+
+	t.Run("posts with attachments are not coalesced", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.EXPECT().DM(botID, userID, gomock.Any()).Return(nil).Times(2)
+
+		queue := NewQueue(postAPI, botID, CoalesceWindow(time.Hour), ChannelRateLimit(0))
+
+		post := &model.Post{}
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{{Text: "attachment"}})
+		require.NoError(t, queue.DMPost(userID, post))
+		_, err := queue.DM(userID, "hello")
+		require.NoError(t, err)
+
+		require.NoError(t, queue.Close())
+	})
+
+	t.Run("pending posts are dropped after the flush timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		postAPI := mock_import.NewMockQueueAPI(ctrl)
+		postAPI.EXPECT().DM(botID, userID, gomock.Any()).Return(nil).Times(1)
+
+		queue := NewQueue(postAPI, botID, ChannelRateLimit(time.Hour), FlushTimeout(10*time.Millisecond))
+		for i := 0; i < 3; i++ {
+			_, err := queue.DM(userID, "hello %d", i)
+			require.NoError(t, err)
+		}
+
+		require.EqualError(t, queue.Close(), "dropped 2 queued posts")
+	})
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, IsTransientError(model.NewAppError("here", "id", nil, "", http.StatusInternalServerError)))
+	assert.True(t, IsTransientError(model.NewAppError("here", "id", nil, "", http.StatusTooManyRequests)))
+	assert.False(t, IsTransientError(model.NewAppError("here", "id", nil, "", http.StatusBadRequest)))
+	assert.False(t, IsTransientError(pluginapi.ErrNotFound))
+	assert.False(t, IsTransientError(errors.New("mock error")))
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Args holds the parsed arguments and flags of a command, keyed by name.
+type Args struct {
+	values map[string]interface{}
+}
+
+// Has returns true if the argument was given or has a default.
+func (a *Args) Has(name string) bool {
+	_, ok := a.values[name]
+	return ok
+}
+
+// String returns the value of a string, text, enum or dynamic argument, or an empty string.
+func (a *Args) String(name string) string {
+	value, _ := a.values[name].(string)
+	return value
+}
+
+// Int returns the value of an int argument, or zero.
+func (a *Args) Int(name string) int64 {
+	value, _ := a.values[name].(int64)
+	return value
+}
+
+// Bool returns the value of a bool argument, or false.
+func (a *Args) Bool(name string) bool {
+	value, _ := a.values[name].(bool)
+	return value
+}
+
+type token struct {
+	value  string
+	quoted bool
+	// start is the position of the token in the command line.
+	start int
+}
+
+// tokenize splits the command line in words. Words between double quotes are kept together.
+func tokenize(line string) ([]token, error) {
+	tokens := []token{}
+	var current *token
+	var b strings.Builder
+	inQuotes := false
+
+	for i, r := range line {
+		switch {
+		case r == '"':
+			if current == nil {
+				current = &token{start: i}
+			}
+			current.quoted = true
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if current != nil {
+				current.value = b.String()
+				tokens = append(tokens, *current)
+				current = nil
+				b.Reset()
+			}
+		default:
+			if current == nil {
+				current = &token{start: i}
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	if inQuotes {
+		return nil, errors.New("missing closing quote")
+	}
+	if current != nil {
+		current.value = b.String()
+		tokens = append(tokens, *current)
+	}
+
+	return tokens, nil
+}
+
+func (c *Command) parse(line string, tokens []token) (*Args, error) {
+	values := map[string]interface{}{}
+	position := 0
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if !t.quoted && strings.HasPrefix(t.value, "--") && len(t.value) > 2 {
+			name := strings.TrimPrefix(t.value, "--")
+			value := ""
+			hasValue := false
+			if index := strings.Index(name, "="); index >= 0 {
+				name, value, hasValue = name[:index], name[index+1:], true
+			}
+
+			flag := c.flag(name)
+			if flag == nil {
+				return nil, errors.Errorf("unknown flag `--%s`", name)
+			}
+
+			if !hasValue {
+				next := i + 1
+				switch {
+				case flag.getType() == ArgTypeBool && (next >= len(tokens) || (tokens[next].value != "true" && tokens[next].value != "false")):
+					value = "true"
+				case next >= len(tokens):
+					return nil, errors.Errorf("flag `--%s` needs a value", name)
+				default:
+					value = tokens[next].value
+					i++
+				}
+			}
+
+			parsed, err := flag.parseValue(value)
+			if err != nil {
+				return nil, err
+			}
+			values[flag.Name] = parsed
+			continue
+		}
+
+		if position >= len(c.Args) {
+			return nil, errors.Errorf("unexpected argument `%s`", t.value)
+		}
+		arg := c.Args[position]
+		position++
+
+		if arg.getType() == ArgTypeText {
+			values[arg.Name] = strings.TrimSpace(line[t.start:])
+			break
+		}
+
+		parsed, err := arg.parseValue(t.value)
+		if err != nil {
+			return nil, err
+		}
+		values[arg.Name] = parsed
+	}
+
+	for _, arg := range append(append([]Arg{}, c.Args...), c.Flags...) {
+		if _, ok := values[arg.Name]; ok {
+			continue
+		}
+		if arg.Required {
+			return nil, errors.Errorf("missing argument `%s`", arg.Name)
+		}
+		if arg.Default != "" {
+			values[arg.Name], _ = arg.parseValue(arg.Default)
+		}
+	}
+
+	return &Args{values: values}, nil
+}
+
+func (c *Command) flag(name string) *Arg {
+	for i := range c.Flags {
+		if c.Flags[i].Name == name {
+			return &c.Flags[i]
+		}
+	}
+	return nil
+}
+
+func (a Arg) parseValue(value string) (interface{}, error) {
+	switch a.getType() {
+	case ArgTypeInt:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("`%s` must be an integer", a.Name)
+		}
+		return i, nil
+	case ArgTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Errorf("`%s` must be true or false", a.Name)
+		}
+		return b, nil
+	case ArgTypeEnum:
+		for _, option := range a.Options {
+			if option == value {
+				return value, nil
+			}
+		}
+		return nil, errors.Errorf("`%s` must be one of %s", a.Name, strings.Join(a.Options, ", "))
+	default:
+		return value, nil
+	}
+}
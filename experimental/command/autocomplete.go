@@ -0,0 +1,90 @@
+package command
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// DefaultAutocompleteURL is the default path, relative to the plugin, under which the suggestions of the dynamic
+// arguments are served.
+const DefaultAutocompleteURL = "/autocomplete"
+
+// DynamicList returns the suggestions of a dynamic argument, e.g. the open tickets of the user. commandArgs holds the
+// user, team and channel the command is typed in, and its Command is what the user has typed so far. The suggestions
+// don't need to be filtered, the server only shows the ones matching the user input.
+type DynamicList func(commandArgs *model.CommandArgs) ([]model.AutocompleteListItem, error)
+
+func (r *router) Mount(muxRouter *mux.Router) {
+	muxRouter.PathPrefix(r.autocompleteURL + "/").Handler(r).Methods(http.MethodGet)
+}
+
+func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := req.Header.Get("Mattermost-User-ID")
+	if userID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, r.autocompleteURL+"/")
+	arg := r.dynamicArg(path)
+	if arg == nil || path == req.URL.Path {
+		http.NotFound(w, req)
+		return
+	}
+
+	query := req.URL.Query()
+	commandArgs := &model.CommandArgs{
+		UserId:    userID,
+		ChannelId: query.Get("channel_id"),
+		TeamId:    query.Get("team_id"),
+		RootId:    query.Get("root_id"),
+		ParentId:  query.Get("parent_id"),
+		SiteURL:   query.Get("site_url"),
+		Command:   query.Get("user_input"),
+	}
+
+	items, err := arg.Fetch(commandArgs)
+	if err != nil {
+		http.Error(w, "Failed to fetch the suggestions", http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []model.AutocompleteListItem{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(model.AutocompleteStaticListItemsToJSON(items))
+}
+
+// dynamicArg returns the dynamic argument or flag at path, e.g. todo/add/ticket, or nil if there is none.
+func (r *router) dynamicArg(path string) *Arg {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] != r.root.Name {
+		return nil
+	}
+
+	cmd := r.root
+	for _, name := range parts[1 : len(parts)-1] {
+		cmd = cmd.subcommand(name)
+		if cmd == nil {
+			return nil
+		}
+	}
+
+	name := parts[len(parts)-1]
+	for _, arg := range append(append([]Arg{}, cmd.Args...), cmd.Flags...) {
+		if arg.Name == name && arg.getType() == ArgTypeDynamic {
+			return &arg
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicList(t *testing.T) {
+	var gotArgs *model.CommandArgs
+	fetchTickets := func(commandArgs *model.CommandArgs) ([]model.AutocompleteListItem, error) {
+		gotArgs = commandArgs
+		if commandArgs.ChannelId == "fail" {
+			return nil, errors.New("failed")
+		}
+		return []model.AutocompleteListItem{{Item: "TICKET-1", HelpText: "Fix it"}}, nil
+	}
+	handler := func(args *Args, commandArgs *model.CommandArgs) (*model.CommandResponse, error) {
+		return &model.CommandResponse{Text: args.String("ticket") + " " + args.String("assignee")}, nil
+	}
+
+	r, err := NewRouter(&Command{
+		Name: "jira",
+		Subcommands: []*Command{{
+			Name: "assign",
+			Args: []Arg{
+				{Name: "ticket", Type: ArgTypeDynamic, Required: true, Fetch: fetchTickets},
+			},
+			Flags: []Arg{
+				{Name: "assignee", Type: ArgTypeDynamic, Fetch: fetchTickets},
+			},
+			Handler: handler,
+		}},
+	}, AutocompleteURL("/api/v1/autocomplete/"))
+	require.NoError(t, err)
+
+	muxRouter := mux.NewRouter()
+	r.Mount(muxRouter)
+
+	t.Run("autocomplete data", func(t *testing.T) {
+		args := r.AutocompleteData().SubCommands[0].Arguments
+		require.Len(t, args, 2)
+		assert.Equal(t, model.AutocompleteArgTypeDynamicList, args[0].Type)
+		assert.Equal(t, "/api/v1/autocomplete/jira/assign/ticket", args[0].Data.(*model.AutocompleteDynamicListArg).FetchURL)
+		assert.Equal(t, "/api/v1/autocomplete/jira/assign/assignee", args[1].Data.(*model.AutocompleteDynamicListArg).FetchURL)
+	})
+
+	t.Run("execute", func(t *testing.T) {
+		resp, err := r.Execute(&model.CommandArgs{Command: "/jira assign --assignee john TICKET-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "TICKET-1 john", resp.Text)
+	})
+
+	serve := func(method, url, userID string) *httptest.ResponseRecorder {
+		gotArgs = nil
+		req := httptest.NewRequest(method, url, nil)
+		if userID != "" {
+			req.Header.Set("Mattermost-User-ID", userID)
+		}
+		w := httptest.NewRecorder()
+		muxRouter.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("suggestions", func(t *testing.T) {
+		w := serve(http.MethodGet, "/api/v1/autocomplete/jira/assign/ticket?user_input=jira+assign+TI&channel_id=channel&team_id=team&user_id=spoofed", "user")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `[{"Item":"TICKET-1","Hint":"","HelpText":"Fix it"}]`, w.Body.String())
+
+		require.NotNil(t, gotArgs)
+		assert.Equal(t, "user", gotArgs.UserId)
+		assert.Equal(t, "channel", gotArgs.ChannelId)
+		assert.Equal(t, "team", gotArgs.TeamId)
+		assert.Equal(t, "jira assign TI", gotArgs.Command)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/autocomplete/jira/assign/ticket", "").Code)
+		assert.Nil(t, gotArgs)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/autocomplete/jira/assign/unknown", "user").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/autocomplete/jira/unknown/ticket", "user").Code)
+		assert.Equal(t, http.StatusInternalServerError, serve(http.MethodGet, "/api/v1/autocomplete/jira/assign/ticket?channel_id=fail", "user").Code)
+	})
+}
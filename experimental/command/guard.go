@@ -0,0 +1,198 @@
+package command
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/i18n"
+)
+
+// Requirement is a condition users must meet to execute a command or call an endpoint, like having a permission or the
+// server having a license.
+type Requirement struct {
+	check  func(client *pluginapi.Client, userID, teamID, channelID string) bool
+	denial *i18n.Message
+	// permission is shown in the denial.
+	permission *model.Permission
+}
+
+// SystemPermission requires the user to have the permission system-wide.
+func SystemPermission(permission *model.Permission) Requirement {
+	return Requirement{
+		check: func(client *pluginapi.Client, userID, _, _ string) bool {
+			return client.User.HasPermissionTo(userID, permission)
+		},
+		denial: &i18n.Message{
+			ID:    "pluginapi.command.system_permission_denied",
+			Other: "You need the `{{.Permission}}` permission to do this.",
+		},
+		permission: permission,
+	}
+}
+
+// TeamPermission requires the user to have the permission on the team the command is executed in.
+func TeamPermission(permission *model.Permission) Requirement {
+	return Requirement{
+		check: func(client *pluginapi.Client, userID, teamID, _ string) bool {
+			return teamID != "" && client.User.HasPermissionToTeam(userID, teamID, permission)
+		},
+		denial: &i18n.Message{
+			ID:    "pluginapi.command.team_permission_denied",
+			Other: "You need the `{{.Permission}}` permission on this team to do this.",
+		},
+		permission: permission,
+	}
+}
+
+// ChannelPermission requires the user to have the permission on the channel the command is executed in.
+func ChannelPermission(permission *model.Permission) Requirement {
+	return Requirement{
+		check: func(client *pluginapi.Client, userID, _, channelID string) bool {
+			return channelID != "" && client.User.HasPermissionToChannel(userID, channelID, permission)
+		},
+		denial: &i18n.Message{
+			ID:    "pluginapi.command.channel_permission_denied",
+			Other: "You need the `{{.Permission}}` permission on this channel to do this.",
+		},
+		permission: permission,
+	}
+}
+
+// SystemAdmin requires the user to be a system admin.
+func SystemAdmin() Requirement {
+	return SystemPermission(model.PERMISSION_MANAGE_SYSTEM)
+}
+
+// ChannelAdmin requires the user to be an admin of the channel the command is executed in.
+func ChannelAdmin() Requirement {
+	return ChannelPermission(model.PERMISSION_MANAGE_CHANNEL_ROLES)
+}
+
+// E10License requires the server to have a Professional or E10 license, or higher, or to be configured for
+// development. See pluginapi.IsE10LicensedOrDevelopment.
+func E10License() Requirement {
+	return Requirement{
+		check: func(client *pluginapi.Client, _, _, _ string) bool {
+			return pluginapi.IsE10LicensedOrDevelopment(client.Configuration.GetConfig(), client.System.GetLicense())
+		},
+		denial: &i18n.Message{
+			ID:    "pluginapi.command.e10_license_required",
+			Other: "This feature requires a Mattermost Professional or Enterprise E10 license.",
+		},
+	}
+}
+
+// E20License requires the server to have an Enterprise or E20 license, or to be configured for development. See
+// pluginapi.IsE20LicensedOrDevelopment.
+func E20License() Requirement {
+	return Requirement{
+		check: func(client *pluginapi.Client, _, _, _ string) bool {
+			return pluginapi.IsE20LicensedOrDevelopment(client.Configuration.GetConfig(), client.System.GetLicense())
+		},
+		denial: &i18n.Message{
+			ID:    "pluginapi.command.e20_license_required",
+			Other: "This feature requires a Mattermost Enterprise or Enterprise E20 license.",
+		},
+	}
+}
+
+// Guard checks requirements before executing command handlers and HTTP handlers. Denials are localized in the locale
+// of the user.
+type Guard interface {
+	// Check returns true if the user meets all requirements. Otherwise, it returns the localized denial of the first
+	// requirement not met.
+	Check(userID, teamID, channelID string, requirements ...Requirement) (bool, string)
+	// Command wraps a command handler. The denial is answered with an ephemeral response.
+	Command(handler Handler, requirements ...Requirement) Handler
+	// HTTP wraps an HTTP handler. The team and the channel are read from the team_id and channel_id route variables
+	// or query parameters. The denial is answered with a 403 status.
+	HTTP(handler http.Handler, requirements ...Requirement) http.Handler
+}
+
+type guard struct {
+	client *pluginapi.Client
+	bundle *i18n.Bundle
+}
+
+// defaultBundle holds no translation, so denials are in English.
+var defaultBundle = goi18n.NewBundle(language.English)
+
+// NewGuard creates a new Guard. Denials are localized with the bundle, or in English if it is nil. Plugins can
+// translate them with the message IDs starting with pluginapi.command.
+func NewGuard(client *pluginapi.Client, bundle *i18n.Bundle) Guard {
+	return &guard{
+		client: client,
+		bundle: bundle,
+	}
+}
+
+func (g *guard) Check(userID, teamID, channelID string, requirements ...Requirement) (bool, string) {
+	for _, requirement := range requirements {
+		if !requirement.check(g.client, userID, teamID, channelID) {
+			return false, g.localize(userID, requirement)
+		}
+	}
+
+	return true, ""
+}
+
+func (g *guard) Command(handler Handler, requirements ...Requirement) Handler {
+	return func(args *Args, commandArgs *model.CommandArgs) (*model.CommandResponse, error) {
+		allowed, denial := g.Check(commandArgs.UserId, commandArgs.TeamId, commandArgs.ChannelId, requirements...)
+		if !allowed {
+			return ephemeral(denial), nil
+		}
+
+		return handler(args, commandArgs)
+	}
+}
+
+func (g *guard) HTTP(handler http.Handler, requirements ...Requirement) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get("Mattermost-User-ID")
+		if userID == "" {
+			http.Error(w, "Not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		allowed, denial := g.Check(userID, requestValue(r, "team_id"), requestValue(r, "channel_id"), requirements...)
+		if !allowed {
+			http.Error(w, denial, http.StatusForbidden)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (g *guard) localize(userID string, requirement Requirement) string {
+	config := &i18n.LocalizeConfig{DefaultMessage: requirement.denial}
+	if requirement.permission != nil {
+		config.TemplateData = map[string]string{"Permission": requirement.permission.Id}
+	}
+
+	var denial string
+	if g.bundle != nil {
+		denial = g.bundle.LocalizeWithConfig(g.bundle.GetUserLocalizer(userID), config)
+	} else {
+		denial, _ = goi18n.NewLocalizer(defaultBundle).Localize(config)
+	}
+	if denial == "" {
+		return requirement.denial.Other
+	}
+
+	return denial
+}
+
+// requestValue returns the route variable with the given name, or the query parameter if there is none.
+func requestValue(r *http.Request, name string) string {
+	if value, ok := mux.Vars(r)[name]; ok {
+		return value
+	}
+	return r.URL.Query().Get(name)
+}
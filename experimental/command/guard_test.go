@@ -0,0 +1,111 @@
+package command
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/i18n"
+)
+
+func TestGuard(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("HasPermissionTo", "admin", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+	api.On("HasPermissionTo", "user", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+	api.On("HasPermissionToTeam", "user", "team", model.PERMISSION_MANAGE_TEAM).Return(true)
+	api.On("HasPermissionToChannel", "user", "channel", model.PERMISSION_MANAGE_CHANNEL_ROLES).Return(false)
+	api.On("GetConfig").Return(&model.Config{})
+	api.On("GetLicense").Return(&model.License{SkuShortName: "professional"})
+	client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+	g := NewGuard(client, nil)
+
+	t.Run("check", func(t *testing.T) {
+		allowed, denial := g.Check("admin", "", "", SystemAdmin(), E10License())
+		assert.True(t, allowed)
+		assert.Empty(t, denial)
+
+		allowed, denial = g.Check("user", "team", "", TeamPermission(model.PERMISSION_MANAGE_TEAM))
+		assert.True(t, allowed)
+		assert.Empty(t, denial)
+
+		allowed, denial = g.Check("user", "", "", TeamPermission(model.PERMISSION_MANAGE_TEAM))
+		assert.False(t, allowed)
+		assert.Equal(t, "You need the `manage_team` permission on this team to do this.", denial)
+
+		allowed, denial = g.Check("user", "team", "channel", ChannelAdmin())
+		assert.False(t, allowed)
+		assert.Equal(t, "You need the `manage_channel_roles` permission on this channel to do this.", denial)
+
+		allowed, denial = g.Check("admin", "", "", SystemAdmin(), E20License())
+		assert.False(t, allowed)
+		assert.Equal(t, "This feature requires a Mattermost Enterprise or Enterprise E20 license.", denial)
+	})
+
+	t.Run("command", func(t *testing.T) {
+		handler := g.Command(func(args *Args, commandArgs *model.CommandArgs) (*model.CommandResponse, error) {
+			return &model.CommandResponse{Text: "done"}, nil
+		}, SystemAdmin())
+
+		resp, err := handler(&Args{}, &model.CommandArgs{UserId: "admin"})
+		require.NoError(t, err)
+		assert.Equal(t, "done", resp.Text)
+
+		resp, err = handler(&Args{}, &model.CommandArgs{UserId: "user"})
+		require.NoError(t, err)
+		assert.Equal(t, model.COMMAND_RESPONSE_TYPE_EPHEMERAL, resp.ResponseType)
+		assert.Equal(t, "You need the `manage_system` permission to do this.", resp.Text)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		handler := g.HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}), TeamPermission(model.PERMISSION_MANAGE_TEAM))
+
+		serve := func(userID, url string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			if userID != "" {
+				r.Header.Set("Mattermost-User-ID", userID)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			return w
+		}
+
+		assert.Equal(t, http.StatusTeapot, serve("user", "/settings?team_id=team").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve("", "/settings?team_id=team").Code)
+
+		w := serve("user", "/settings")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "on this team")
+	})
+
+	t.Run("localized", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		err = ioutil.WriteFile(filepath.Join(dir, "active.de.json"), []byte(`{
+			"pluginapi.command.system_permission_denied": "Du brauchst die Berechtigung `+"`{{.Permission}}`"+`."
+		}`), 0600)
+		require.NoError(t, err)
+
+		api.On("GetBundlePath").Return(dir, nil)
+		api.On("GetUser", "user").Return(&model.User{Id: "user", Locale: "de"}, nil)
+		bundle, err := i18n.InitBundle(api, ".")
+		require.NoError(t, err)
+
+		allowed, denial := NewGuard(client, bundle).Check("user", "", "", SystemAdmin())
+		assert.False(t, allowed)
+		assert.Equal(t, "Du brauchst die Berechtigung `manage_system`.", denial)
+	})
+}
@@ -0,0 +1,151 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// hint returns the usage of the arguments and flags of the command, e.g. `<name> [--private]`.
+func (c *Command) hint() string {
+	if len(c.Subcommands) > 0 {
+		names := []string{}
+		for _, sub := range c.Subcommands {
+			names = append(names, sub.Name)
+		}
+		return "[" + strings.Join(append(names, helpCommand), "|") + "]"
+	}
+
+	parts := []string{}
+	for _, arg := range c.Args {
+		if arg.Required {
+			parts = append(parts, "<"+arg.Name+">")
+		} else {
+			parts = append(parts, "["+arg.Name+"]")
+		}
+	}
+	for _, flag := range c.Flags {
+		usage := "--" + flag.Name
+		if flag.getType() != ArgTypeBool {
+			usage += " <" + string(flag.displayType()) + ">"
+		}
+		if !flag.Required {
+			usage = "[" + usage + "]"
+		}
+		parts = append(parts, usage)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// help returns the help of the command, in Markdown. path is the command line leading to it, e.g. /jira issue.
+func (c *Command) help(path []string) string {
+	usage := strings.Join(path, " ")
+	if hint := c.hint(); hint != "" {
+		usage += " " + hint
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "`%s`", usage)
+	if c.Description != "" {
+		fmt.Fprintf(&b, "\n%s", c.Description)
+	}
+
+	if len(c.Subcommands) > 0 {
+		b.WriteString("\n\n**Commands:**")
+		for _, sub := range c.Subcommands {
+			fmt.Fprintf(&b, "\n- `%s %s`: %s", strings.Join(path, " "), sub.Name, sub.Description)
+		}
+		fmt.Fprintf(&b, "\n- `%s %s`: Show this help.", strings.Join(path, " "), helpCommand)
+	}
+
+	if len(c.Args) > 0 {
+		b.WriteString("\n\n**Arguments:**")
+		for _, arg := range c.Args {
+			fmt.Fprintf(&b, "\n- `%s`%s", arg.Name, arg.helpDetails())
+		}
+	}
+
+	if len(c.Flags) > 0 {
+		b.WriteString("\n\n**Flags:**")
+		for _, flag := range c.Flags {
+			fmt.Fprintf(&b, "\n- `--%s`%s", flag.Name, flag.helpDetails())
+		}
+	}
+
+	return b.String()
+}
+
+func (a Arg) helpDetails() string {
+	details := []string{}
+	if a.Required {
+		details = append(details, "required")
+	}
+	if a.getType() == ArgTypeEnum {
+		details = append(details, "one of "+strings.Join(a.Options, ", "))
+	} else {
+		details = append(details, string(a.displayType()))
+	}
+	if a.Default != "" {
+		details = append(details, "default "+a.Default)
+	}
+
+	help := " (" + strings.Join(details, ", ") + ")"
+	if a.Description != "" {
+		help += ": " + a.Description
+	}
+	return help
+}
+
+// displayType returns the type of the argument shown to the users.
+func (a Arg) displayType() ArgType {
+	if a.getType() == ArgTypeDynamic {
+		return ArgTypeString
+	}
+	return a.getType()
+}
+
+// autocompleteData returns the autocomplete data of the command. fetchURL is the URL, relative to the plugin, under
+// which the suggestions of its dynamic arguments are served.
+func (c *Command) autocompleteData(fetchURL string) *model.AutocompleteData {
+	ad := model.NewAutocompleteData(c.Name, c.hint(), c.Description)
+
+	for _, sub := range c.Subcommands {
+		ad.AddCommand(sub.autocompleteData(fetchURL + "/" + sub.Name))
+	}
+	if len(c.Subcommands) > 0 {
+		ad.AddCommand(model.NewAutocompleteData(helpCommand, "", "Show the help"))
+	}
+
+	for _, arg := range c.Args {
+		arg.addAutocompleteArgument(ad, "", fetchURL)
+	}
+	for _, flag := range c.Flags {
+		flag.addAutocompleteArgument(ad, flag.Name, fetchURL)
+	}
+
+	return ad
+}
+
+// addAutocompleteArgument adds the argument to the autocomplete data, positional if name is empty.
+func (a Arg) addAutocompleteArgument(ad *model.AutocompleteData, name, fetchURL string) {
+	switch a.getType() {
+	case ArgTypeDynamic:
+		ad.AddNamedDynamicListArgument(name, a.Description, fetchURL+"/"+a.Name, a.Required)
+	case ArgTypeBool, ArgTypeEnum:
+		options := a.Options
+		if a.getType() == ArgTypeBool {
+			options = []string{"true", "false"}
+		}
+		items := []model.AutocompleteListItem{}
+		for _, option := range options {
+			items = append(items, model.AutocompleteListItem{Item: option})
+		}
+		ad.AddNamedStaticListArgument(name, a.Description, a.Required, items)
+	case ArgTypeInt:
+		ad.AddNamedTextArgument(name, a.Description, "<"+a.Name+">", "^-?[0-9]+$", a.Required)
+	default:
+		ad.AddNamedTextArgument(name, a.Description, "<"+a.Name+">", "", a.Required)
+	}
+}
@@ -0,0 +1,306 @@
+package command
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// ArgType is the type of the value of an argument or flag.
+type ArgType string
+
+const (
+	// ArgTypeString is a single word, or several words between double quotes.
+	ArgTypeString ArgType = "string"
+	// ArgTypeText is the rest of the line. It can only be used by the last positional argument, so the flags must be
+	// written before it.
+	ArgTypeText ArgType = "text"
+	// ArgTypeInt is an integer.
+	ArgTypeInt ArgType = "int"
+	// ArgTypeBool is true or false. A bool flag given without value is true.
+	ArgTypeBool ArgType = "bool"
+	// ArgTypeEnum is one of the Options of the argument.
+	ArgTypeEnum ArgType = "enum"
+	// ArgTypeDynamic is a single word, suggested by the Fetch function of the argument while the user is typing.
+	ArgTypeDynamic ArgType = "dynamic"
+)
+
+// helpCommand is the subcommand showing the help of the command it follows.
+const helpCommand = "help"
+
+// Arg declares a positional argument or a flag of a command.
+type Arg struct {
+	Name        string
+	Description string
+	// Type defaults to ArgTypeString.
+	Type     ArgType
+	Required bool
+	// Options are the values allowed by ArgTypeEnum.
+	Options []string
+	// Default is the value used when the argument is not given.
+	Default string
+	// Fetch returns the suggestions of an ArgTypeDynamic argument.
+	Fetch DynamicList
+}
+
+// Handler executes a command with its parsed arguments.
+type Handler func(args *Args, commandArgs *model.CommandArgs) (*model.CommandResponse, error)
+
+// Command declares a slash command or one of its subcommands. The declaration is used to parse the arguments, and to
+// generate the help and the autocomplete data.
+type Command struct {
+	Name        string
+	Description string
+	// Args are the positional arguments, in order.
+	Args []Arg
+	// Flags are the arguments given as --name value.
+	Flags []Arg
+	// Subcommands cannot be declared with Args or Flags.
+	Subcommands []*Command
+	// Handler is called when the command is executed. Commands with subcommands may have no handler, in which case
+	// their help is shown.
+	Handler Handler
+}
+
+// Router executes a slash command declared with Command.
+type Router interface {
+	// Command returns the command to register with the SlashCommandService.
+	Command() *model.Command
+	// AutocompleteData returns the autocomplete data generated from the declaration.
+	AutocompleteData() *model.AutocompleteData
+	// Execute parses the command and calls the handler of the subcommand. Invalid commands and help requests are
+	// answered with an ephemeral response. Errors are the ones returned by the handlers.
+	Execute(commandArgs *model.CommandArgs) (*model.CommandResponse, error)
+	// Mount registers the endpoints serving the suggestions of the dynamic arguments in the router of the plugin.
+	Mount(r *mux.Router)
+	// ServeHTTP implements http.Handler, serving the suggestions of the dynamic arguments.
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+// RouterOption configures a Router.
+type RouterOption func(*router)
+
+// AutocompleteURL sets the path, relative to the plugin, under which the suggestions of the dynamic arguments are
+// served. It defaults to DefaultAutocompleteURL.
+func AutocompleteURL(url string) RouterOption {
+	return func(r *router) {
+		r.autocompleteURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+type router struct {
+	root            *Command
+	autocomplete    *model.AutocompleteData
+	autocompleteURL string
+}
+
+// NewRouter creates a Router for the command, whose name is the trigger. An error is returned if the declaration is not
+// valid.
+func NewRouter(root *Command, options ...RouterOption) (Router, error) {
+	err := root.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &router{
+		root:            root,
+		autocompleteURL: DefaultAutocompleteURL,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	r.autocomplete = root.autocompleteData(r.autocompleteURL + "/" + root.Name)
+
+	err = r.autocomplete.IsValid()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid autocomplete data")
+	}
+
+	return r, nil
+}
+
+func (r *router) Command() *model.Command {
+	return &model.Command{
+		Trigger:          r.root.Name,
+		AutoComplete:     true,
+		AutoCompleteDesc: r.root.Description,
+		AutoCompleteHint: r.root.hint(),
+		AutocompleteData: r.autocomplete,
+	}
+}
+
+func (r *router) AutocompleteData() *model.AutocompleteData {
+	return r.autocomplete
+}
+
+func (r *router) Execute(commandArgs *model.CommandArgs) (*model.CommandResponse, error) {
+	tokens, err := tokenize(commandArgs.Command)
+	if err != nil {
+		return ephemeral("Invalid command: " + err.Error() + "."), nil
+	}
+	if len(tokens) == 0 || strings.TrimPrefix(tokens[0].value, "/") != r.root.Name {
+		return ephemeral("Unknown command."), nil
+	}
+
+	cmd := r.root
+	path := []string{"/" + r.root.Name}
+	tokens = tokens[1:]
+	for len(cmd.Subcommands) > 0 && len(tokens) > 0 {
+		name := tokens[0].value
+		if name == helpCommand || name == "--help" {
+			return ephemeral(cmd.help(path)), nil
+		}
+
+		sub := cmd.subcommand(name)
+		if sub == nil {
+			return ephemeral("Unknown command `" + name + "`.\n\n" + cmd.help(path)), nil
+		}
+
+		cmd = sub
+		path = append(path, sub.Name)
+		tokens = tokens[1:]
+	}
+
+	if cmd.Handler == nil {
+		return ephemeral(cmd.help(path)), nil
+	}
+
+	for _, t := range tokens {
+		if !t.quoted && t.value == "--help" {
+			return ephemeral(cmd.help(path)), nil
+		}
+	}
+
+	args, err := cmd.parse(commandArgs.Command, tokens)
+	if err != nil {
+		return ephemeral("Invalid command: " + err.Error() + ".\n\n" + cmd.help(path)), nil
+	}
+
+	return cmd.Handler(args, commandArgs)
+}
+
+func (c *Command) subcommand(name string) *Command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (c *Command) validate() error {
+	if c.Name == "" || strings.ToLower(c.Name) != c.Name || strings.ContainsAny(c.Name, " \t\n/") {
+		return errors.Errorf("invalid command name %q", c.Name)
+	}
+
+	if len(c.Subcommands) > 0 {
+		if len(c.Args) > 0 || len(c.Flags) > 0 {
+			return errors.Errorf("command %s cannot have subcommands and arguments", c.Name)
+		}
+
+		names := map[string]bool{helpCommand: true}
+		for _, sub := range c.Subcommands {
+			if names[sub.Name] {
+				return errors.Errorf("command %s: duplicated or reserved subcommand %s", c.Name, sub.Name)
+			}
+			names[sub.Name] = true
+
+			err := sub.validate()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if c.Handler == nil {
+		return errors.Errorf("command %s has no handler", c.Name)
+	}
+
+	names := map[string]bool{}
+	optional := false
+	for i, arg := range c.Args {
+		err := arg.validate(names)
+		if err != nil {
+			return errors.Wrapf(err, "command %s", c.Name)
+		}
+
+		switch {
+		case arg.getType() == ArgTypeText && i != len(c.Args)-1:
+			return errors.Errorf("command %s: text argument %s must be the last one", c.Name, arg.Name)
+		case arg.Required && optional:
+			return errors.Errorf("command %s: required argument %s cannot follow optional ones", c.Name, arg.Name)
+		case !arg.Required && !arg.isList():
+			// Autocomplete only allows optional positional arguments chosen from a list.
+			return errors.Errorf("command %s: optional argument %s must be a bool, an enum or a dynamic, or a flag", c.Name, arg.Name)
+		}
+		optional = optional || !arg.Required
+	}
+
+	for _, flag := range c.Flags {
+		if flag.getType() == ArgTypeText {
+			return errors.Errorf("command %s: flag %s cannot be a text", c.Name, flag.Name)
+		}
+
+		err := flag.validate(names)
+		if err != nil {
+			return errors.Wrapf(err, "command %s", c.Name)
+		}
+	}
+
+	return nil
+}
+
+func (a Arg) validate(names map[string]bool) error {
+	if a.Name == "" || strings.ContainsAny(a.Name, " \t\n=/") || strings.HasPrefix(a.Name, "-") {
+		return errors.Errorf("invalid argument name %q", a.Name)
+	}
+	if names[a.Name] {
+		return errors.Errorf("duplicated argument %s", a.Name)
+	}
+	names[a.Name] = true
+
+	switch a.getType() {
+	case ArgTypeString, ArgTypeText, ArgTypeInt, ArgTypeBool:
+	case ArgTypeEnum:
+		if len(a.Options) == 0 {
+			return errors.Errorf("enum argument %s has no options", a.Name)
+		}
+	case ArgTypeDynamic:
+		if a.Fetch == nil {
+			return errors.Errorf("dynamic argument %s has no fetch function", a.Name)
+		}
+	default:
+		return errors.Errorf("argument %s has an unknown type %s", a.Name, a.Type)
+	}
+
+	if a.Default != "" {
+		_, err := a.parseValue(a.Default)
+		if err != nil {
+			return errors.Wrapf(err, "invalid default of argument %s", a.Name)
+		}
+	}
+
+	return nil
+}
+
+func (a Arg) getType() ArgType {
+	if a.Type == "" {
+		return ArgTypeString
+	}
+	return a.Type
+}
+
+func (a Arg) isList() bool {
+	return a.getType() == ArgTypeBool || a.getType() == ArgTypeEnum || a.getType() == ArgTypeDynamic
+}
+
+func ephemeral(text string) *model.CommandResponse {
+	return &model.CommandResponse{
+		ResponseType: model.COMMAND_RESPONSE_TYPE_EPHEMERAL,
+		Text:         text,
+	}
+}
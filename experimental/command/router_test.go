@@ -0,0 +1,180 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter(t *testing.T) {
+	var gotArgs *Args
+	handler := func(args *Args, commandArgs *model.CommandArgs) (*model.CommandResponse, error) {
+		gotArgs = args
+		return &model.CommandResponse{Text: "done"}, nil
+	}
+
+	root := &Command{
+		Name:        "todo",
+		Description: "Manage your todos",
+		Subcommands: []*Command{
+			{
+				Name:        "add",
+				Description: "Add a todo",
+				Args: []Arg{
+					{Name: "message", Type: ArgTypeText, Required: true},
+				},
+				Flags: []Arg{
+					{Name: "priority", Type: ArgTypeEnum, Options: []string{"low", "high"}, Default: "low"},
+					{Name: "days", Type: ArgTypeInt},
+					{Name: "private", Type: ArgTypeBool},
+				},
+				Handler: handler,
+			},
+			{
+				Name:        "list",
+				Description: "List your todos",
+				Args: []Arg{
+					{Name: "user", Required: true},
+					{Name: "done", Type: ArgTypeBool},
+				},
+				Handler: handler,
+			},
+		},
+	}
+
+	r, err := NewRouter(root)
+	require.NoError(t, err)
+
+	execute := func(line string) *model.CommandResponse {
+		gotArgs = nil
+		resp, err := r.Execute(&model.CommandArgs{Command: line})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("flags and text", func(t *testing.T) {
+		resp := execute(`/todo add --priority=high --days 3 --private buy "some" milk`)
+		assert.Equal(t, "done", resp.Text)
+		require.NotNil(t, gotArgs)
+		assert.Equal(t, `buy "some" milk`, gotArgs.String("message"))
+		assert.Equal(t, "high", gotArgs.String("priority"))
+		assert.Equal(t, int64(3), gotArgs.Int("days"))
+		assert.True(t, gotArgs.Bool("private"))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		execute("/todo add buy milk")
+		require.NotNil(t, gotArgs)
+		assert.Equal(t, "low", gotArgs.String("priority"))
+		assert.False(t, gotArgs.Has("days"))
+		assert.False(t, gotArgs.Bool("private"))
+	})
+
+	t.Run("quoted positional", func(t *testing.T) {
+		execute(`/todo list "john doe" true`)
+		require.NotNil(t, gotArgs)
+		assert.Equal(t, "john doe", gotArgs.String("user"))
+		assert.True(t, gotArgs.Bool("done"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for line, message := range map[string]string{
+			"/todo add --days x buy":     "`days` must be an integer",
+			"/todo add --priority no a":  "`priority` must be one of low, high",
+			"/todo add --unknown a":      "unknown flag `--unknown`",
+			"/todo add":                  "missing argument `message`",
+			"/todo list john false more": "unexpected argument `more`",
+			`/todo list "john`:           "missing closing quote",
+		} {
+			resp := execute(line)
+			assert.Nil(t, gotArgs, line)
+			assert.Equal(t, model.COMMAND_RESPONSE_TYPE_EPHEMERAL, resp.ResponseType)
+			assert.Contains(t, resp.Text, "Invalid command: "+message+".", line)
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		for _, line := range []string{"/todo", "/todo help", "/todo --help", "/todo unknown"} {
+			resp := execute(line)
+			assert.Nil(t, gotArgs)
+			assert.Contains(t, resp.Text, "`/todo [add|list|help]`\nManage your todos", line)
+			assert.Contains(t, resp.Text, "- `/todo add`: Add a todo", line)
+		}
+
+		resp := execute("/todo add --help")
+		assert.Nil(t, gotArgs)
+		assert.Contains(t, resp.Text, "`/todo add <message> [--priority <enum>] [--days <int>] [--private]`")
+		assert.Contains(t, resp.Text, "- `--priority` (one of low, high, default low)")
+	})
+
+	t.Run("autocomplete", func(t *testing.T) {
+		cmd := r.Command()
+		assert.Equal(t, "todo", cmd.Trigger)
+		assert.True(t, cmd.AutoComplete)
+
+		ad := r.AutocompleteData()
+		require.NoError(t, ad.IsValid())
+		require.Len(t, ad.SubCommands, 3)
+		assert.Equal(t, "help", ad.SubCommands[2].Trigger)
+
+		add := ad.SubCommands[0]
+		require.Len(t, add.Arguments, 4)
+		assert.Equal(t, "", add.Arguments[0].Name)
+		assert.Equal(t, model.AutocompleteArgTypeText, add.Arguments[0].Type)
+		assert.Equal(t, "priority", add.Arguments[1].Name)
+		assert.Equal(t, model.AutocompleteArgTypeStaticList, add.Arguments[1].Type)
+	})
+}
+
+func TestNewRouterValidation(t *testing.T) {
+	handler := func(args *Args, commandArgs *model.CommandArgs) (*model.CommandResponse, error) {
+		return nil, nil
+	}
+
+	for name, cmd := range map[string]*Command{
+		"uppercase name": {Name: "Todo", Handler: handler},
+		"no handler":     {Name: "todo"},
+		"subcommands and args": {
+			Name:        "todo",
+			Args:        []Arg{{Name: "a", Required: true}},
+			Subcommands: []*Command{{Name: "add", Handler: handler}},
+		},
+		"reserved subcommand": {
+			Name:        "todo",
+			Subcommands: []*Command{{Name: "help", Handler: handler}},
+		},
+		"text not last": {
+			Name:    "todo",
+			Args:    []Arg{{Name: "a", Type: ArgTypeText, Required: true}, {Name: "b", Required: true}},
+			Handler: handler,
+		},
+		"optional string": {
+			Name:    "todo",
+			Args:    []Arg{{Name: "a"}},
+			Handler: handler,
+		},
+		"enum without options": {
+			Name:    "todo",
+			Flags:   []Arg{{Name: "a", Type: ArgTypeEnum}},
+			Handler: handler,
+		},
+		"invalid default": {
+			Name:    "todo",
+			Flags:   []Arg{{Name: "a", Type: ArgTypeInt, Default: "x"}},
+			Handler: handler,
+		},
+		"duplicated argument": {
+			Name:    "todo",
+			Args:    []Arg{{Name: "a", Required: true}},
+			Flags:   []Arg{{Name: "a"}},
+			Handler: handler,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewRouter(cmd)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// DialogOpener opens interactive dialogs. It is implemented by pluginapi.FrontendService.
+type DialogOpener interface {
+	OpenInteractiveDialog(dialog model.OpenDialogRequest) error
+}
+
+// ValidateDialogSubmission checks the submission of a dialog against the constraints of its
+// elements: required fields, text lengths, numbers, options and booleans. It returns the errors
+// by element name, or nil if the submission is valid.
+func ValidateDialogSubmission(elements []model.DialogElement, submission map[string]interface{}) map[string]string {
+	errs := map[string]string{}
+	for _, element := range elements {
+		value := submission[element.Name]
+		if value == nil || value == "" {
+			if !element.Optional {
+				errs[element.Name] = "This field is required."
+			}
+			continue
+		}
+
+		if message := validateDialogValue(element, value); message != "" {
+			errs[element.Name] = message
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func validateDialogValue(element model.DialogElement, value interface{}) string {
+	switch element.Type {
+	case "text", "textarea":
+		if element.SubType == "number" {
+			if _, ok := value.(float64); ok {
+				return ""
+			}
+		}
+
+		text, ok := value.(string)
+		if !ok {
+			return "Invalid value."
+		}
+
+		if element.SubType == "number" {
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return "Must be a number."
+			}
+		}
+
+		length := utf8.RuneCountInString(text)
+		if element.MinLength > 0 && length < element.MinLength {
+			return fmt.Sprintf("Must be at least %d characters.", element.MinLength)
+		}
+		if element.MaxLength > 0 && length > element.MaxLength {
+			return fmt.Sprintf("Must be at most %d characters.", element.MaxLength)
+		}
+	case "select", "radio":
+		option, ok := value.(string)
+		if !ok {
+			return "Invalid value."
+		}
+
+		if element.DataSource != "" || len(element.Options) == 0 {
+			return ""
+		}
+
+		for _, o := range element.Options {
+			if o.Value == option {
+				return ""
+			}
+		}
+		return "Invalid option."
+	case "bool":
+		switch value {
+		case true, false, "true", "false":
+		default:
+			return "Invalid value."
+		}
+	}
+
+	return ""
+}
+
+// DialogError writes a dialog submission response with a generic error and errors by element
+// name. Either may be empty.
+func DialogError(w http.ResponseWriter, errorMessage string, errs map[string]string) {
+	response := model.SubmitDialogResponse{
+		Error:  errorMessage,
+		Errors: errs,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(response.ToJson())
+}
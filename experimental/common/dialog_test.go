@@ -0,0 +1,53 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDialogSubmission(t *testing.T) {
+	elements := []model.DialogElement{
+		{Name: "name", Type: "text", MinLength: 2, MaxLength: 5},
+		{Name: "age", Type: "text", SubType: "number", Optional: true},
+		{Name: "color", Type: "radio", Options: []*model.PostActionOptions{{Text: "Red", Value: "red"}}},
+		{Name: "user", Type: "select", DataSource: "users", Optional: true},
+		{Name: "notify", Type: "bool", Optional: true},
+	}
+
+	for name, tc := range map[string]struct {
+		submission     map[string]interface{}
+		expectedErrors map[string]string
+	}{
+		"valid": {
+			submission:     map[string]interface{}{"name": "Ana", "age": "42", "color": "red", "user": "user-id", "notify": true},
+			expectedErrors: nil,
+		},
+		"valid number and empty optional fields": {
+			submission:     map[string]interface{}{"name": "Ana", "age": float64(42), "color": "red", "user": ""},
+			expectedErrors: nil,
+		},
+		"missing required fields": {
+			submission:     map[string]interface{}{},
+			expectedErrors: map[string]string{"name": "This field is required.", "color": "This field is required."},
+		},
+		"invalid values": {
+			submission: map[string]interface{}{"name": "A", "age": "old", "color": "blue", "notify": "maybe"},
+			expectedErrors: map[string]string{
+				"name":   "Must be at least 2 characters.",
+				"age":    "Must be a number.",
+				"color":  "Invalid option.",
+				"notify": "Invalid value.",
+			},
+		},
+		"too long": {
+			submission:     map[string]interface{}{"name": "Ana María", "color": "red"},
+			expectedErrors: map[string]string{"name": "Must be at most 5 characters."},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedErrors, ValidateDialogSubmission(elements, tc.submission))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package flow
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/common"
+	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+)
+
+// dialogPath is appended to the handler URL of the flow to receive dialog submissions.
+const dialogPath = "/dialog"
+
+type dialogState struct {
+	FlowID string
+	Step   int
+	PostID string
+}
+
+// WithDialogs allows steps implementing steps.DialogStep to open interactive dialogs through the
+// given opener, usually the pluginapi FrontendService.
+func WithDialogs(opener common.DialogOpener) ControllerOption {
+	return func(fc *flowController) {
+		fc.dialogOpener = opener
+	}
+}
+
+// OpenDialog opens the dialog of step for the user, from an action on the post postID.
+func (fc *flowController) OpenDialog(userID, triggerID string, stepIndex int, postID string) error {
+	if fc.dialogOpener == nil {
+		return errors.New("dialogs are not enabled for this flow")
+	}
+
+	dialogStep, ok := fc.flow.Step(stepIndex).(steps.DialogStep)
+	if !ok {
+		return errors.Errorf("step %d does not support dialogs", stepIndex)
+	}
+
+	state, err := json.Marshal(dialogState{
+		FlowID: fc.id,
+		Step:   stepIndex,
+		PostID: postID,
+	})
+	if err != nil {
+		return err
+	}
+
+	dialog := dialogStep.Dialog()
+	dialog.State = string(state)
+
+	return fc.dialogOpener.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       fc.GetHandlerURL() + dialogPath,
+		Dialog:    dialog,
+	})
+}
+
+// SubmitDialog validates the submission of the dialog of step. If it is valid, the submission is
+// set as the value of the step and the flow moves forward. Otherwise, the errors are returned by
+// element name.
+func (fc *flowController) SubmitDialog(userID string, stepIndex int, submission map[string]interface{}) (map[string]string, error) {
+	step := fc.flow.Step(stepIndex)
+	dialogStep, ok := step.(steps.DialogStep)
+	if !ok {
+		return nil, errors.Errorf("step %d does not support dialogs", stepIndex)
+	}
+
+	errs := common.ValidateDialogSubmission(dialogStep.Dialog().Elements, submission)
+	for name, message := range dialogStep.ValidateSubmission(submission) {
+		if errs == nil {
+			errs = map[string]string{}
+		}
+		if _, ok := errs[name]; !ok {
+			errs[name] = message
+		}
+	}
+	if len(errs) > 0 {
+		return errs, nil
+	}
+
+	err := fc.SetProperty(userID, step.GetPropertyName(), submission)
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := fc.store.GetPostID(userID, step.GetPropertyName())
+	if err != nil {
+		return nil, err
+	}
+
+	if postID != "" {
+		post := &model.Post{Id: postID}
+		model.ParseSlackAttachment(post, []*model.SlackAttachment{step.ResponseSlackAttachment(submission)})
+		err = fc.UpdatePost(post)
+		if err != nil {
+			fc.Logger.Debugf("error updating step post, %s", err.Error())
+		}
+	}
+
+	return nil, fc.NextStep(userID, stepIndex, submission)
+}
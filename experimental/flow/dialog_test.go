@@ -0,0 +1,70 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+)
+
+type fakeDialogOpener struct {
+	requests []model.OpenDialogRequest
+}
+
+func (o *fakeDialogOpener) OpenInteractiveDialog(dialog model.OpenDialogRequest) error {
+	o.requests = append(o.requests, dialog)
+	return nil
+}
+
+func TestDialogStep(t *testing.T) {
+	userID := model.NewId()
+
+	p := &fakePoster{}
+	opener := &fakeDialogOpener{}
+	client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+	f := NewFlow([]steps.Step{
+		steps.NewDialogStep("Profile", "Tell us about you", "profile", "Fill in", "Save", []model.DialogElement{
+			{DisplayName: "Name", Name: "name", Type: "text"},
+			{DisplayName: "Color", Name: "color", Type: "radio", Options: []*model.PostActionOptions{{Text: "Red", Value: "red"}}},
+		}, func(submission map[string]interface{}) map[string]string {
+			if submission["name"] == "root" {
+				return map[string]string{"name": "This name is reserved."}
+			}
+			return nil
+		}),
+		steps.NewEmptyStep("Done", "Thanks"),
+	}, "/flow", nil)
+	store, err := NewFlowStore(*client, "flow")
+	require.NoError(t, err)
+	fc := NewFlowController(p, logger.NewNilLogger(), "/plugins/test", f, store, fakePropertyStore{}, WithDialogs(opener))
+
+	require.NoError(t, fc.Start(userID))
+	require.NoError(t, fc.OpenDialog(userID, "trigger-id", 1, p.postIDs[0]))
+	require.Len(t, opener.requests, 1)
+	assert.Equal(t, "trigger-id", opener.requests[0].TriggerId)
+	assert.Equal(t, "/plugins/test/flow/dialog", opener.requests[0].URL)
+	assert.Len(t, opener.requests[0].Dialog.Elements, 2)
+	assert.NotEmpty(t, opener.requests[0].Dialog.State)
+
+	errs, err := fc.SubmitDialog(userID, 1, map[string]interface{}{"name": "root"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "This name is reserved.", "color": "This field is required."}, errs)
+
+	errs, err = fc.SubmitDialog(userID, 1, map[string]interface{}{"name": "Ana", "color": "red"})
+	require.NoError(t, err)
+	assert.Nil(t, errs)
+
+	require.Len(t, p.updated, 1)
+	assert.Equal(t, p.postIDs[0], p.updated[0].Id)
+	assert.Equal(t, "Name: **Ana**\nColor: **Red**", p.updated[0].Attachments()[0].Text)
+
+	_, index, err := fc.GetCurrentStep(userID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, index)
+}
@@ -0,0 +1,197 @@
+package flow
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+)
+
+var (
+	// ErrFlowPaused is returned by ValidateAction when the flow of the user is paused.
+	ErrFlowPaused = errors.New("the flow is paused")
+	// ErrFlowExpired is returned by ValidateAction when the flow of the user has expired.
+	ErrFlowExpired = errors.New("the flow has expired")
+	// ErrStaleStep is returned by ValidateAction when the action comes from a step post that is
+	// no longer the current one.
+	ErrStaleStep = errors.New("the step is no longer active")
+)
+
+// WithExpiry expires flows in which the user has not moved forward for the given duration.
+// The buttons of the current step are disabled and the flow is paused. If resumeMessage is not
+// empty, the user is sent a DM with a button labeled resumeLabel, which resumes the flow where it
+// was left off.
+//
+// Flows are expired when the user uses a stale step, or when ExpireFlows is called.
+func WithExpiry(expiry time.Duration, resumeMessage, resumeLabel string) ControllerOption {
+	return func(fc *flowController) {
+		fc.expiry = expiry
+		fc.resumeMessage = resumeMessage
+		fc.resumeLabel = resumeLabel
+	}
+}
+
+// ValidateAction checks that an action on the post postID of step can be handled, returning
+// ErrFlowPaused, ErrFlowExpired or ErrStaleStep otherwise. An expired flow is expired on the spot.
+func (fc *flowController) ValidateAction(userID string, stepIndex int, postID string) error {
+	paused, err := fc.store.IsPaused(userID)
+	if err != nil {
+		return err
+	}
+
+	if paused {
+		return ErrFlowPaused
+	}
+
+	current, err := fc.getFlowStep(userID)
+	if err != nil {
+		return err
+	}
+
+	step := fc.flow.Step(current)
+	if step == nil || current != stepIndex {
+		return ErrStaleStep
+	}
+
+	expired, err := fc.isExpired(userID)
+	if err != nil {
+		return err
+	}
+
+	if expired {
+		err = fc.expire(userID, current)
+		if err != nil {
+			return err
+		}
+		return ErrFlowExpired
+	}
+
+	currentPostID, err := fc.store.GetPostID(userID, step.GetPropertyName())
+	if err != nil {
+		return err
+	}
+
+	if currentPostID != postID {
+		return ErrStaleStep
+	}
+
+	return nil
+}
+
+// ExpireFlows expires the flows in which the users have not moved forward within the duration
+// set with WithExpiry. Call it periodically, e.g. from a job scheduled with cluster.Schedule.
+func (fc *flowController) ExpireFlows() error {
+	if fc.expiry <= 0 {
+		return nil
+	}
+
+	userIDs, err := fc.store.ListUserIDs()
+	if err != nil {
+		return errors.Wrap(err, "failed to list users")
+	}
+
+	for _, userID := range userIDs {
+		paused, err := fc.store.IsPaused(userID)
+		if err != nil {
+			return err
+		}
+
+		if paused {
+			continue
+		}
+
+		expired, err := fc.isExpired(userID)
+		if err != nil {
+			return err
+		}
+
+		if !expired {
+			continue
+		}
+
+		current, err := fc.getFlowStep(userID)
+		if err != nil {
+			return err
+		}
+
+		err = fc.expire(userID, current)
+		if err != nil {
+			return errors.Wrapf(err, "failed to expire flow for user %s", userID)
+		}
+	}
+
+	return nil
+}
+
+func (fc *flowController) isExpired(userID string) (bool, error) {
+	if fc.expiry <= 0 {
+		return false, nil
+	}
+
+	updatedAt, err := fc.store.GetUpdatedAt(userID)
+	if err != nil {
+		return false, err
+	}
+
+	if updatedAt == 0 {
+		return false, nil
+	}
+
+	return time.Since(time.Unix(0, updatedAt*int64(time.Millisecond))) > fc.expiry, nil
+}
+
+// expire pauses the flow, disables the buttons of the current step and offers the user to resume.
+func (fc *flowController) expire(userID string, stepIndex int) error {
+	err := fc.store.SetPaused(userID, true)
+	if err != nil {
+		return err
+	}
+
+	fc.disableStepPost(userID, stepIndex)
+	fc.track(EventFlowAbandon, userID, stepIndex)
+
+	if fc.resumeMessage == "" {
+		return nil
+	}
+
+	attachment := &model.SlackAttachment{
+		Text:     fc.resumeMessage,
+		Fallback: fc.resumeMessage,
+		Actions: []*model.PostAction{
+			steps.NewNavigationAction(fc.resumeLabel, fc.GetHandlerURL(), stepIndex, steps.NavigationResume),
+		},
+	}
+	setFlowID(attachment, fc.id)
+
+	_, err = fc.DMWithAttachments(userID, attachment)
+	return err
+}
+
+func (fc *flowController) disableStepPost(userID string, stepIndex int) {
+	step := fc.flow.Step(stepIndex)
+	if step == nil {
+		return
+	}
+
+	postID, err := fc.store.GetPostID(userID, step.GetPropertyName())
+	if err != nil || postID == "" {
+		return
+	}
+
+	attachment := step.PostSlackAttachment(fc.GetHandlerURL(), stepIndex)
+	for _, action := range attachment.Actions {
+		action.Disabled = true
+	}
+
+	post := &model.Post{Id: postID}
+	model.ParseSlackAttachment(post, []*model.SlackAttachment{attachment})
+
+	err = fc.UpdatePost(post)
+	if err != nil {
+		fc.Logger.Debugf("error disabling step post, %s", err.Error())
+	}
+
+	_ = fc.store.RemovePostID(userID, step.GetPropertyName())
+}
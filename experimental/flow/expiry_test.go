@@ -0,0 +1,115 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+)
+
+type fakeTracker struct {
+	events []string
+}
+
+func (t *fakeTracker) TrackEvent(event string, properties map[string]interface{}) error {
+	t.events = append(t.events, event)
+	return nil
+}
+
+func (t *fakeTracker) TrackUserEvent(event, userID string, properties map[string]interface{}) error {
+	return t.TrackEvent(event, properties)
+}
+
+func (t *fakeTracker) OnConfigurationChange(config *model.Config) {}
+
+func (t *fakeTracker) Flush() error {
+	return nil
+}
+
+func TestExpiry(t *testing.T) {
+	userID := model.NewId()
+
+	setup := func() (Controller, Store, *fakePoster, *fakeTracker) {
+		p := &fakePoster{}
+		tracker := &fakeTracker{}
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		store, err := NewFlowStore(*client, "flow")
+		require.NoError(t, err)
+		fc := NewFlowController(p, logger.NewNilLogger(), "/plugins/test", newTestFlow(), store, fakePropertyStore{},
+			WithExpiry(time.Hour, "Do you want to continue?", "Resume"),
+			WithTracker(tracker),
+		)
+		return fc, store, p, tracker
+	}
+
+	t.Run("validate action", func(t *testing.T) {
+		fc, _, p, _ := setup()
+
+		require.NoError(t, fc.Start(userID))
+		firstPostID := p.postIDs[0]
+		require.NoError(t, fc.ValidateAction(userID, 1, firstPostID))
+		require.Equal(t, ErrStaleStep, fc.ValidateAction(userID, 1, model.NewId()))
+
+		require.NoError(t, fc.NextStep(userID, 1, "true"))
+		require.Equal(t, ErrStaleStep, fc.ValidateAction(userID, 1, firstPostID))
+		require.NoError(t, fc.ValidateAction(userID, 2, p.postIDs[1]))
+
+		require.NoError(t, fc.Pause(userID))
+		require.Equal(t, ErrFlowPaused, fc.ValidateAction(userID, 2, p.postIDs[1]))
+	})
+
+	t.Run("expire flows", func(t *testing.T) {
+		fc, store, p, tracker := setup()
+
+		require.NoError(t, fc.Start(userID))
+		require.NoError(t, fc.ExpireFlows())
+		assert.Empty(t, p.updated)
+
+		require.NoError(t, store.SetUpdatedAt(userID, model.GetMillis()-2*time.Hour.Milliseconds()))
+		require.NoError(t, fc.ExpireFlows())
+
+		require.Len(t, p.updated, 1)
+		assert.Equal(t, p.postIDs[0], p.updated[0].Id)
+		for _, action := range p.updated[0].Attachments()[0].Actions {
+			assert.True(t, action.Disabled)
+		}
+
+		paused, err := fc.IsPaused(userID)
+		require.NoError(t, err)
+		assert.True(t, paused)
+
+		require.Len(t, p.attachments, 2)
+		resume := p.attachments[1].Actions[0]
+		assert.Equal(t, steps.NavigationResume, resume.Integration.Context[steps.ContextNavigationKey])
+
+		require.NoError(t, fc.Resume(userID))
+		require.NoError(t, fc.ValidateAction(userID, 1, p.postIDs[2]))
+		assert.Equal(t, []string{EventFlowStart, EventFlowAbandon}, tracker.events)
+	})
+
+	t.Run("expire on action", func(t *testing.T) {
+		fc, store, p, tracker := setup()
+
+		require.NoError(t, fc.Start(userID))
+		require.NoError(t, store.SetUpdatedAt(userID, model.GetMillis()-2*time.Hour.Milliseconds()))
+		require.Equal(t, ErrFlowExpired, fc.ValidateAction(userID, 1, p.postIDs[0]))
+		assert.Len(t, p.updated, 1)
+		assert.Equal(t, []string{EventFlowStart, EventFlowAbandon}, tracker.events)
+	})
+
+	t.Run("track completion", func(t *testing.T) {
+		fc, _, _, tracker := setup()
+
+		require.NoError(t, fc.Start(userID))
+		require.NoError(t, fc.NextStep(userID, 1, "true"))
+		require.NoError(t, fc.NextStep(userID, 2, "true"))
+		assert.Equal(t, []string{EventFlowStart, EventFlowStepComplete, EventFlowStepComplete, EventFlowComplete}, tracker.events)
+	})
+}
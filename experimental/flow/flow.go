@@ -10,6 +10,9 @@ type Flow interface {
 	URL() string
 	Length() int
 	FlowDone(userID string)
+	// Next returns the index of the step following step from, given the value set on it and all
+	// the properties collected so far. It returns 0 when the flow is done.
+	Next(from int, value interface{}, properties map[string]interface{}) int
 }
 
 type flow struct {
@@ -54,3 +57,17 @@ func (f *flow) FlowDone(userID string) {
 		f.onFlowDone(userID)
 	}
 }
+
+func (f *flow) Next(from int, value interface{}, properties map[string]interface{}) int {
+	step := f.Step(from)
+	if step == nil {
+		return 0
+	}
+
+	next := from + 1 + step.ShouldSkip(value)
+	if next < 1 || next > f.Length() {
+		return 0
+	}
+
+	return next
+}
@@ -3,10 +3,16 @@ package flow
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
 	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
+	"github.com/mattermost/mattermost-plugin-api/experimental/common"
 	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+	"github.com/mattermost/mattermost-plugin-api/experimental/telemetry"
 )
 
 type Controller interface {
@@ -17,15 +23,53 @@ type Controller interface {
 	GetFlow() Flow
 	Cancel(userID string) error
 	SetProperty(userID, propertyName string, value interface{}) error
+	Back(userID string) error
+	Restart(userID string) error
+	GetFlowID() string
+	Pause(userID string) error
+	Resume(userID string) error
+	IsPaused(userID string) (bool, error)
+	ValidateAction(userID string, step int, postID string) error
+	ExpireFlows() error
+	OpenDialog(userID, triggerID string, step int, postID string) error
+	SubmitDialog(userID string, step int, submission map[string]interface{}) (map[string]string, error)
+}
+
+// ControllerOption defines each option that can be passed in the creation of the Controller.
+type ControllerOption func(*flowController)
+
+// WithNavigation adds buttons to every step post, allowing the user to go back to the previous
+// step or to restart the flow. An empty label hides the corresponding button.
+func WithNavigation(backLabel, restartLabel string) ControllerOption {
+	return func(fc *flowController) {
+		fc.backLabel = backLabel
+		fc.restartLabel = restartLabel
+	}
+}
+
+// WithFlowID identifies the flow in the context of every action it posts, so that several flows
+// can share the same handler URL. See Registry.
+func WithFlowID(id string) ControllerOption {
+	return func(fc *flowController) {
+		fc.id = id
+	}
 }
 
 type flowController struct {
 	poster.Poster
 	logger.Logger
+	id            string
 	flow          Flow
 	store         Store
 	propertyStore PropertyStore
 	pluginURL     string
+	backLabel     string
+	restartLabel  string
+	expiry        time.Duration
+	resumeMessage string
+	resumeLabel   string
+	tracker       telemetry.Tracker
+	dialogOpener  common.DialogOpener
 }
 
 func NewFlowController(
@@ -35,6 +79,7 @@ func NewFlowController(
 	flow Flow,
 	flowStore Store,
 	propertyStore PropertyStore,
+	options ...ControllerOption,
 ) Controller {
 	fc := &flowController{
 		Poster:        p,
@@ -45,6 +90,10 @@ func NewFlowController(
 		pluginURL:     pluginURL,
 	}
 
+	for _, option := range options {
+		option(fc)
+	}
+
 	for _, step := range flow.Steps() {
 		ftf := step.GetFreetextFetcher()
 		if ftf != nil {
@@ -62,24 +111,165 @@ func (fc *flowController) GetFlow() Flow {
 	return fc.flow
 }
 
+func (fc *flowController) GetFlowID() string {
+	return fc.id
+}
+
 func (fc *flowController) SetProperty(userID, propertyName string, value interface{}) error {
-	return fc.propertyStore.SetProperty(userID, propertyName, value)
+	err := fc.propertyStore.SetProperty(userID, propertyName, value)
+	if err != nil {
+		return err
+	}
+
+	properties, err := fc.store.GetProperties(userID)
+	if err != nil {
+		return err
+	}
+	properties[propertyName] = value
+
+	return fc.store.SetProperties(userID, properties)
 }
 
 func (fc *flowController) Start(userID string) error {
-	err := fc.setFlowStep(userID, 1)
+	err := fc.store.SetPaused(userID, false)
 	if err != nil {
 		return err
 	}
+
+	err = fc.clearState(userID)
+	if err != nil {
+		return err
+	}
+
+	err = fc.setFlowStep(userID, 1)
+	if err != nil {
+		return err
+	}
+
+	fc.track(EventFlowStart, userID, 1)
 	return fc.processStep(userID, 1)
 }
 
+// Back moves the flow to the last step the user answered, skipping any empty step.
+func (fc *flowController) Back(userID string) error {
+	history, err := fc.store.GetHistory(userID)
+	if err != nil {
+		return err
+	}
+
+	previous := 0
+	for len(history) > 0 {
+		previous = history[len(history)-1]
+		history = history[:len(history)-1]
+
+		step := fc.flow.Step(previous)
+		if step != nil && !step.IsEmpty() {
+			break
+		}
+		previous = 0
+	}
+
+	if previous == 0 {
+		return errors.New("there is no previous step")
+	}
+
+	current, err := fc.getFlowStep(userID)
+	if err != nil {
+		return err
+	}
+	fc.deleteStepPost(userID, current)
+
+	err = fc.store.SetHistory(userID, history)
+	if err != nil {
+		return err
+	}
+
+	err = fc.setFlowStep(userID, previous)
+	if err != nil {
+		return err
+	}
+
+	return fc.processStep(userID, previous)
+}
+
+// Restart discards the properties collected so far and starts the flow from the first step.
+func (fc *flowController) Restart(userID string) error {
+	current, err := fc.getFlowStep(userID)
+	if err != nil {
+		return err
+	}
+	fc.deleteStepPost(userID, current)
+
+	return fc.Start(userID)
+}
+
+// Pause removes the post of the current step, keeping the state of the flow until Resume is called.
+func (fc *flowController) Pause(userID string) error {
+	current, err := fc.getFlowStep(userID)
+	if err != nil {
+		return err
+	}
+
+	if current == 0 {
+		return errors.New("the flow is not running")
+	}
+
+	err = fc.store.SetPaused(userID, true)
+	if err != nil {
+		return err
+	}
+
+	fc.deleteStepPost(userID, current)
+	return nil
+}
+
+// Resume posts the current step of a paused flow again.
+func (fc *flowController) Resume(userID string) error {
+	paused, err := fc.store.IsPaused(userID)
+	if err != nil {
+		return err
+	}
+
+	if !paused {
+		return errors.New("the flow is not paused")
+	}
+
+	current, err := fc.getFlowStep(userID)
+	if err != nil {
+		return err
+	}
+
+	err = fc.store.SetPaused(userID, false)
+	if err != nil {
+		return err
+	}
+
+	if current == 0 {
+		return nil
+	}
+
+	return fc.processStep(userID, current)
+}
+
+func (fc *flowController) IsPaused(userID string) (bool, error) {
+	return fc.store.IsPaused(userID)
+}
+
 func (fc *flowController) NextStep(userID string, from int, value interface{}) error {
 	stepIndex, err := fc.getFlowStep(userID)
 	if err != nil {
 		return err
 	}
 
+	paused, err := fc.store.IsPaused(userID)
+	if err != nil {
+		return err
+	}
+
+	if paused {
+		return nil
+	}
+
 	if stepIndex != from {
 		// We are beyond the step we were supposed to come from, so we understand this step has already been processed.
 		// Used to avoid rapid firing on the Slack Attachments.
@@ -93,20 +283,40 @@ func (fc *flowController) NextStep(userID string, from int, value interface{}) e
 		fc.Logger.Debugf("error removing post id, %s", err.Error())
 	}
 
-	skip := step.ShouldSkip(value)
-	stepIndex += 1 + skip
-	if stepIndex > fc.flow.Length() {
+	properties, err := fc.store.GetProperties(userID)
+	if err != nil {
+		return err
+	}
+
+	if !step.IsEmpty() {
+		fc.track(EventFlowStepComplete, userID, stepIndex)
+	}
+
+	next := fc.flow.Next(stepIndex, value, properties)
+	if next == 0 {
 		_ = fc.removeFlowStep(userID)
+		_ = fc.clearState(userID)
+		fc.track(EventFlowComplete, userID, stepIndex)
 		fc.flow.FlowDone(userID)
 		return nil
 	}
 
-	err = fc.setFlowStep(userID, stepIndex)
+	history, err := fc.store.GetHistory(userID)
 	if err != nil {
 		return err
 	}
 
-	return fc.processStep(userID, stepIndex)
+	err = fc.store.SetHistory(userID, append(history, stepIndex))
+	if err != nil {
+		return err
+	}
+
+	err = fc.setFlowStep(userID, next)
+	if err != nil {
+		return err
+	}
+
+	return fc.processStep(userID, next)
 }
 
 func (fc *flowController) GetCurrentStep(userID string) (steps.Step, int, error) {
@@ -152,9 +362,43 @@ func (fc *flowController) Cancel(userID string) error {
 		return err
 	}
 
+	fc.track(EventFlowCancel, userID, stepIndex)
 	return nil
 }
 
+func (fc *flowController) clearState(userID string) error {
+	err := fc.store.SetHistory(userID, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fc.store.SetUpdatedAt(userID, 0)
+	if err != nil {
+		return err
+	}
+
+	return fc.store.SetProperties(userID, nil)
+}
+
+func (fc *flowController) deleteStepPost(userID string, stepIndex int) {
+	step := fc.flow.Step(stepIndex)
+	if step == nil {
+		return
+	}
+
+	postID, err := fc.store.GetPostID(userID, step.GetPropertyName())
+	if err != nil || postID == "" {
+		return
+	}
+
+	err = fc.DeletePost(postID)
+	if err != nil {
+		fc.Logger.Debugf("error deleting step post, %s", err.Error())
+	}
+
+	_ = fc.store.RemovePostID(userID, step.GetPropertyName())
+}
+
 func (fc *flowController) setFlowStep(userID string, step int) error {
 	return fc.store.SetCurrentStep(userID, step)
 }
@@ -181,7 +425,13 @@ func (fc *flowController) processStep(userID string, i int) error {
 		fc.Errorf("Store nil")
 	}
 
-	postID, err := fc.DMWithAttachments(userID, step.PostSlackAttachment(fc.GetHandlerURL(), i))
+	attachment := step.PostSlackAttachment(fc.GetHandlerURL(), i)
+	if !step.IsEmpty() {
+		fc.addNavigation(userID, attachment, i)
+	}
+	setFlowID(attachment, fc.id)
+
+	postID, err := fc.DMWithAttachments(userID, attachment)
 	if err != nil {
 		return err
 	}
@@ -195,6 +445,11 @@ func (fc *flowController) processStep(userID string, i int) error {
 		return err
 	}
 
+	err = fc.store.SetUpdatedAt(userID, model.GetMillis())
+	if err != nil {
+		return err
+	}
+
 	ftf := step.GetFreetextFetcher()
 	if ftf == nil {
 		return nil
@@ -211,3 +466,33 @@ func (fc *flowController) processStep(userID string, i int) error {
 	ftf.StartFetching(userID, string(payload))
 	return nil
 }
+
+func (fc *flowController) addNavigation(userID string, attachment *model.SlackAttachment, i int) {
+	if fc.backLabel != "" {
+		history, err := fc.store.GetHistory(userID)
+		if err == nil && len(history) > 0 {
+			attachment.Actions = append(attachment.Actions, steps.NewNavigationAction(fc.backLabel, fc.GetHandlerURL(), i, steps.NavigationBack))
+		}
+	}
+
+	if fc.restartLabel != "" && i != 1 {
+		attachment.Actions = append(attachment.Actions, steps.NewNavigationAction(fc.restartLabel, fc.GetHandlerURL(), i, steps.NavigationRestart))
+	}
+}
+
+// setFlowID adds the flow ID to the context of every action of the attachment.
+func setFlowID(attachment *model.SlackAttachment, id string) {
+	if attachment == nil || id == "" {
+		return
+	}
+
+	for _, action := range attachment.Actions {
+		if action.Integration == nil {
+			continue
+		}
+		if action.Integration.Context == nil {
+			action.Integration.Context = map[string]interface{}{}
+		}
+		action.Integration.Context[steps.ContextFlowIDKey] = id
+	}
+}
@@ -18,6 +18,10 @@ func (fc *flowController) ftOnFetch(message, payload string) {
 		return
 	}
 
+	if paused, _ := fc.store.IsPaused(ftInfo.UserID); paused {
+		return
+	}
+
 	err = fc.SetProperty(ftInfo.UserID, ftInfo.Property, message)
 	if err != nil {
 		fc.Logger.Errorf("cannot set free text property %s, err=%s", ftInfo.Property, err)
@@ -0,0 +1,141 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+)
+
+// Condition decides whether a transition applies, given the properties collected so far.
+type Condition func(properties map[string]interface{}) bool
+
+// Transition moves a flow to the step named To when its Condition holds.
+type Transition struct {
+	To string
+	// Condition is optional. A transition without a condition always applies.
+	Condition Condition
+}
+
+// Node is a named step of a graph flow, along with the transitions leaving it.
+//
+// Transitions are evaluated in order, and the first one whose condition holds is followed. The
+// flow is done when no transition applies.
+type Node struct {
+	Name        string
+	Step        steps.Step
+	Transitions []Transition
+}
+
+// PropertyEquals returns a condition holding when the named property has the given value.
+// Values are compared by their string representation, so that e.g. the value set by a simple
+// step matches both "true" and true.
+func PropertyEquals(name string, value interface{}) Condition {
+	return func(properties map[string]interface{}) bool {
+		v, ok := properties[name]
+		if !ok {
+			return false
+		}
+		return fmt.Sprint(v) == fmt.Sprint(value)
+	}
+}
+
+// PropertyIsSet returns a condition holding when the named property has a non empty value.
+func PropertyIsSet(name string) Condition {
+	return func(properties map[string]interface{}) bool {
+		v, ok := properties[name]
+		return ok && v != nil && fmt.Sprint(v) != ""
+	}
+}
+
+// Not negates a condition.
+func Not(c Condition) Condition {
+	return func(properties map[string]interface{}) bool {
+		return !c(properties)
+	}
+}
+
+type graphFlow struct {
+	flow
+	nodes   []Node
+	indexes map[string]int
+}
+
+// NewGraphFlow creates a flow from a graph of named steps. The flow starts at the first node.
+//
+// The graph is validated: node names must be unique and not empty, every node must have a step,
+// every transition must lead to an existing node, and every node must be reachable from the first one.
+// Note that the ShouldSkip value of the steps is ignored, as transitions decide the next step.
+func NewGraphFlow(nodes []Node, url string, onFlowDone func(userID string)) (Flow, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("flow must have at least one node")
+	}
+
+	indexes := map[string]int{}
+	stepList := make([]steps.Step, 0, len(nodes))
+	for i, node := range nodes {
+		if node.Name == "" {
+			return nil, errors.Errorf("node %d has no name", i+1)
+		}
+		if _, ok := indexes[node.Name]; ok {
+			return nil, errors.Errorf("node name %s is not unique", node.Name)
+		}
+		if node.Step == nil {
+			return nil, errors.Errorf("node %s has no step", node.Name)
+		}
+
+		indexes[node.Name] = i + 1
+		stepList = append(stepList, node.Step)
+	}
+
+	for _, node := range nodes {
+		for _, t := range node.Transitions {
+			if _, ok := indexes[t.To]; !ok {
+				return nil, errors.Errorf("node %s has a transition to unknown node %s", node.Name, t.To)
+			}
+		}
+	}
+
+	reached := map[string]bool{nodes[0].Name: true}
+	pending := []Node{nodes[0]}
+	for len(pending) > 0 {
+		node := pending[0]
+		pending = pending[1:]
+		for _, t := range node.Transitions {
+			if !reached[t.To] {
+				reached[t.To] = true
+				pending = append(pending, nodes[indexes[t.To]-1])
+			}
+		}
+	}
+	for _, node := range nodes {
+		if !reached[node.Name] {
+			return nil, errors.Errorf("node %s is not reachable", node.Name)
+		}
+	}
+
+	return &graphFlow{
+		flow: flow{
+			steps:      stepList,
+			url:        url,
+			onFlowDone: onFlowDone,
+		},
+		nodes:   nodes,
+		indexes: indexes,
+	}, nil
+}
+
+func (f *graphFlow) Next(from int, value interface{}, properties map[string]interface{}) int {
+	if from < 1 || from > len(f.nodes) {
+		return 0
+	}
+
+	for _, t := range f.nodes[from-1].Transitions {
+		if t.Condition == nil || t.Condition(properties) {
+			return f.indexes[t.To]
+		}
+	}
+
+	return 0
+}
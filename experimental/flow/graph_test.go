@@ -0,0 +1,92 @@
+package flow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+)
+
+func TestNewGraphFlow(t *testing.T) {
+	step := steps.NewEmptyStep("title", "message")
+
+	for name, tc := range map[string]struct {
+		nodes         []Node
+		expectedError string
+	}{
+		"no nodes": {
+			nodes:         nil,
+			expectedError: "flow must have at least one node",
+		},
+		"unnamed node": {
+			nodes:         []Node{{Step: step}},
+			expectedError: "node 1 has no name",
+		},
+		"duplicate name": {
+			nodes:         []Node{{Name: "a", Step: step, Transitions: []Transition{{To: "a"}}}, {Name: "a", Step: step}},
+			expectedError: "node name a is not unique",
+		},
+		"missing step": {
+			nodes:         []Node{{Name: "a"}},
+			expectedError: "node a has no step",
+		},
+		"unknown transition": {
+			nodes:         []Node{{Name: "a", Step: step, Transitions: []Transition{{To: "b"}}}},
+			expectedError: "node a has a transition to unknown node b",
+		},
+		"unreachable node": {
+			nodes:         []Node{{Name: "a", Step: step}, {Name: "b", Step: step}},
+			expectedError: "node b is not reachable",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewGraphFlow(tc.nodes, "/flow", nil)
+			require.EqualError(t, err, tc.expectedError)
+			assert.Nil(t, f)
+		})
+	}
+}
+
+func TestGraphFlowNext(t *testing.T) {
+	step := steps.NewEmptyStep("title", "message")
+
+	f, err := NewGraphFlow([]Node{
+		{Name: "start", Step: step, Transitions: []Transition{
+			{To: "admin", Condition: PropertyEquals("is_admin", true)},
+			{To: "user"},
+		}},
+		{Name: "user", Step: step, Transitions: []Transition{
+			{To: "end", Condition: PropertyIsSet("team")},
+		}},
+		{Name: "admin", Step: step, Transitions: []Transition{
+			{To: "end", Condition: Not(PropertyIsSet("team"))},
+		}},
+		{Name: "end", Step: step},
+	}, "/flow", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, f.Length())
+	assert.Equal(t, 3, f.Next(1, nil, map[string]interface{}{"is_admin": "true"}))
+	assert.Equal(t, 2, f.Next(1, nil, map[string]interface{}{"is_admin": "false"}))
+	assert.Equal(t, 2, f.Next(1, nil, map[string]interface{}{}))
+	assert.Equal(t, 4, f.Next(2, nil, map[string]interface{}{"team": "team-id"}))
+	assert.Equal(t, 0, f.Next(2, nil, map[string]interface{}{"team": ""}))
+	assert.Equal(t, 4, f.Next(3, nil, map[string]interface{}{}))
+	assert.Equal(t, 0, f.Next(4, nil, map[string]interface{}{}))
+	assert.Equal(t, 0, f.Next(5, nil, map[string]interface{}{}))
+}
+
+func TestFlowNext(t *testing.T) {
+	f := NewFlow([]steps.Step{
+		steps.NewSimpleStep("title", "message", "property", "yes", "no", "yes", "no", 1, 0),
+		steps.NewEmptyStep("title", "message"),
+		steps.NewEmptyStep("title", "message"),
+	}, "/flow", nil)
+
+	assert.Equal(t, 3, f.Next(1, "true", nil))
+	assert.Equal(t, 2, f.Next(1, "false", nil))
+	assert.Equal(t, 3, f.Next(2, nil, nil))
+	assert.Equal(t, 0, f.Next(3, nil, nil))
+}
@@ -16,7 +16,8 @@ import (
 )
 
 type fh struct {
-	fc Controller
+	fc       Controller
+	registry Registry
 }
 
 func Init(r *mux.Router, fc Controller) {
@@ -26,6 +27,44 @@ func Init(r *mux.Router, fc Controller) {
 
 	flowRouter := r.PathPrefix("/").Subrouter()
 	flowRouter.HandleFunc(fc.GetFlow().URL(), fh.handleFlow).Methods(http.MethodPost)
+	flowRouter.HandleFunc(fc.GetFlow().URL()+dialogPath, fh.handleDialog).Methods(http.MethodPost)
+}
+
+// InitRegistry registers the handlers of all flows in the registry. Flows sharing the same URL
+// are told apart by the flow ID in the action context. It must be called after all flows have
+// been registered.
+func InitRegistry(r *mux.Router, registry Registry) {
+	flowRouter := r.PathPrefix("/").Subrouter()
+
+	urls := map[string]bool{}
+	for _, fc := range registry.Controllers() {
+		url := fc.GetFlow().URL()
+		if urls[url] {
+			continue
+		}
+		urls[url] = true
+
+		fh := &fh{
+			fc:       fc,
+			registry: registry,
+		}
+		flowRouter.HandleFunc(url, fh.handleFlow).Methods(http.MethodPost)
+		flowRouter.HandleFunc(url+dialogPath, fh.handleDialog).Methods(http.MethodPost)
+	}
+}
+
+// getController returns the controller of the flow with the given ID, defaulting to the flow the
+// handler was registered for.
+func (fh *fh) getController(flowID string) Controller {
+	if flowID == "" || flowID == fh.fc.GetFlowID() {
+		return fh.fc
+	}
+
+	if fh.registry == nil {
+		return nil
+	}
+
+	return fh.registry.Get(flowID)
 }
 
 func (fh *fh) handleFlow(w http.ResponseWriter, r *http.Request) {
@@ -41,6 +80,13 @@ func (fh *fh) handleFlow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	flowID, _ := request.Context[steps.ContextFlowIDKey].(string)
+	fc := fh.getController(flowID)
+	if fc == nil {
+		common.SlackAttachmentError(w, "Error: unknown flow")
+		return
+	}
+
 	rawStep, ok := request.Context[steps.ContextStepKey].(string)
 	if !ok {
 		common.SlackAttachmentError(w, "Error: missing step number")
@@ -53,12 +99,42 @@ func (fh *fh) handleFlow(w http.ResponseWriter, r *http.Request) {
 		common.SlackAttachmentError(w, "Error: cannot parse step number")
 	}
 
-	step := fh.fc.GetFlow().Step(stepNumber)
+	step := fc.GetFlow().Step(stepNumber)
 	if step == nil {
 		common.SlackAttachmentError(w, fmt.Sprintf("Error: There is no step %d.", step))
 		return
 	}
 
+	navigation, isNavigation := request.Context[steps.ContextNavigationKey].(string)
+	if isNavigation && navigation == steps.NavigationResume {
+		fh.handleResume(w, fc, userID)
+		return
+	}
+
+	err = fc.ValidateAction(userID, stepNumber, request.PostId)
+	if err != nil {
+		common.SlackAttachmentError(w, validationErrorMessage(err))
+		return
+	}
+
+	if isNavigation {
+		fh.handleNavigation(w, fc, userID, navigation)
+		return
+	}
+
+	if dialog, _ := request.Context[steps.ContextDialogKey].(bool); dialog {
+		err = fc.OpenDialog(userID, request.TriggerId, stepNumber, request.PostId)
+		if err != nil {
+			common.SlackAttachmentError(w, "Error: cannot open the dialog, err="+err.Error())
+			return
+		}
+
+		response := model.PostActionIntegrationResponse{}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(response.ToJson())
+		return
+	}
+
 	property, ok := request.Context[steps.ContextPropertyKey].(string)
 	if !ok {
 		common.SlackAttachmentError(w, "Error: missing property name")
@@ -66,12 +142,34 @@ func (fh *fh) handleFlow(w http.ResponseWriter, r *http.Request) {
 	}
 
 	value, ok := request.Context[steps.ContextButtonValueKey]
+	if !ok {
+		value, ok = request.Context[steps.ContextOptionValueKey]
+	}
 	if !ok {
 		common.SlackAttachmentError(w, "Error: missing value")
 		return
 	}
 
-	err = fh.fc.SetProperty(userID, property, value)
+	if partial, _ := request.Context[steps.ContextPartialKey].(bool); partial {
+		partialStep, ok := step.(steps.PartialStep)
+		if !ok {
+			common.SlackAttachmentError(w, "Error: step does not support partial values")
+			return
+		}
+
+		response := model.PostActionIntegrationResponse{}
+		post := model.Post{}
+		attachment := partialStep.PartialSlackAttachment(fc.GetHandlerURL(), stepNumber, value)
+		setFlowID(attachment, fc.GetFlowID())
+		model.ParseSlackAttachment(&post, []*model.SlackAttachment{attachment})
+		response.Update = &post
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(response.ToJson())
+		return
+	}
+
+	err = fc.SetProperty(userID, property, value)
 	if err != nil {
 		common.SlackAttachmentError(w, "There has been a problem setting the property, err="+err.Error())
 		return
@@ -85,5 +183,114 @@ func (fh *fh) handleFlow(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_, _ = w.Write(response.ToJson())
 
-	_ = fh.fc.NextStep(userID, stepNumber, value)
+	_ = fc.NextStep(userID, stepNumber, value)
+}
+
+func (fh *fh) handleNavigation(w http.ResponseWriter, fc Controller, userID string, navigation string) {
+	var err error
+	switch navigation {
+	case steps.NavigationBack:
+		err = fc.Back(userID)
+	case steps.NavigationRestart:
+		err = fc.Restart(userID)
+	default:
+		common.SlackAttachmentError(w, "Error: unknown navigation "+navigation)
+		return
+	}
+
+	if err != nil {
+		common.SlackAttachmentError(w, "Error: cannot navigate, err="+err.Error())
+		return
+	}
+
+	response := model.PostActionIntegrationResponse{}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(response.ToJson())
+}
+
+func (fh *fh) handleResume(w http.ResponseWriter, fc Controller, userID string) {
+	paused, err := fc.IsPaused(userID)
+	if err != nil {
+		common.SlackAttachmentError(w, "Error: cannot get the flow state, err="+err.Error())
+		return
+	}
+
+	if !paused {
+		common.SlackAttachmentError(w, "Error: this flow is not paused.")
+		return
+	}
+
+	err = fc.Resume(userID)
+	if err != nil {
+		common.SlackAttachmentError(w, "Error: cannot resume the flow, err="+err.Error())
+		return
+	}
+
+	response := model.PostActionIntegrationResponse{}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(response.ToJson())
+}
+
+func (fh *fh) handleDialog(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	if userID == "" {
+		common.DialogError(w, "Error: Not authorized", nil)
+		return
+	}
+
+	request := model.SubmitDialogRequestFromJson(r.Body)
+	if request == nil {
+		common.DialogError(w, "Error: invalid request", nil)
+		return
+	}
+
+	if request.Cancelled {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var state dialogState
+	err := json.Unmarshal([]byte(request.State), &state)
+	if err != nil {
+		common.DialogError(w, "Error: cannot parse dialog state", nil)
+		return
+	}
+
+	fc := fh.getController(state.FlowID)
+	if fc == nil {
+		common.DialogError(w, "Error: unknown flow", nil)
+		return
+	}
+
+	err = fc.ValidateAction(userID, state.Step, state.PostID)
+	if err != nil {
+		common.DialogError(w, validationErrorMessage(err), nil)
+		return
+	}
+
+	errs, err := fc.SubmitDialog(userID, state.Step, request.Submission)
+	if err != nil {
+		common.DialogError(w, "Error: cannot submit the dialog, err="+err.Error(), nil)
+		return
+	}
+
+	if len(errs) > 0 {
+		common.DialogError(w, "", errs)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func validationErrorMessage(err error) string {
+	switch err {
+	case ErrFlowPaused:
+		return "Error: this flow is paused."
+	case ErrFlowExpired:
+		return "Error: this flow has expired."
+	case ErrStaleStep:
+		return "Error: this step is no longer active."
+	default:
+		return "Error: cannot get the flow state, err=" + err.Error()
+	}
 }
@@ -34,6 +34,20 @@ func (m *MockController) EXPECT() *MockControllerMockRecorder {
 	return m.recorder
 }
 
+// Back mocks base method
+func (m *MockController) Back(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Back", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Back indicates an expected call of Back
+func (mr *MockControllerMockRecorder) Back(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Back", reflect.TypeOf((*MockController)(nil).Back), arg0)
+}
+
 // Cancel mocks base method
 func (m *MockController) Cancel(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -48,6 +62,20 @@ func (mr *MockControllerMockRecorder) Cancel(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockController)(nil).Cancel), arg0)
 }
 
+// ExpireFlows mocks base method
+func (m *MockController) ExpireFlows() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireFlows")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireFlows indicates an expected call of ExpireFlows
+func (mr *MockControllerMockRecorder) ExpireFlows() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireFlows", reflect.TypeOf((*MockController)(nil).ExpireFlows))
+}
+
 // GetCurrentStep mocks base method
 func (m *MockController) GetCurrentStep(arg0 string) (steps.Step, int, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +106,20 @@ func (mr *MockControllerMockRecorder) GetFlow() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlow", reflect.TypeOf((*MockController)(nil).GetFlow))
 }
 
+// GetFlowID mocks base method
+func (m *MockController) GetFlowID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlowID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetFlowID indicates an expected call of GetFlowID
+func (mr *MockControllerMockRecorder) GetFlowID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlowID", reflect.TypeOf((*MockController)(nil).GetFlowID))
+}
+
 // GetHandlerURL mocks base method
 func (m *MockController) GetHandlerURL() string {
 	m.ctrl.T.Helper()
@@ -92,6 +134,21 @@ func (mr *MockControllerMockRecorder) GetHandlerURL() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHandlerURL", reflect.TypeOf((*MockController)(nil).GetHandlerURL))
 }
 
+// IsPaused mocks base method
+func (m *MockController) IsPaused(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPaused", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsPaused indicates an expected call of IsPaused
+func (mr *MockControllerMockRecorder) IsPaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPaused", reflect.TypeOf((*MockController)(nil).IsPaused), arg0)
+}
+
 // NextStep mocks base method
 func (m *MockController) NextStep(arg0 string, arg1 int, arg2 interface{}) error {
 	m.ctrl.T.Helper()
@@ -106,6 +163,62 @@ func (mr *MockControllerMockRecorder) NextStep(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextStep", reflect.TypeOf((*MockController)(nil).NextStep), arg0, arg1, arg2)
 }
 
+// OpenDialog mocks base method
+func (m *MockController) OpenDialog(arg0, arg1 string, arg2 int, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDialog", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OpenDialog indicates an expected call of OpenDialog
+func (mr *MockControllerMockRecorder) OpenDialog(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDialog", reflect.TypeOf((*MockController)(nil).OpenDialog), arg0, arg1, arg2, arg3)
+}
+
+// Pause mocks base method
+func (m *MockController) Pause(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause
+func (mr *MockControllerMockRecorder) Pause(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockController)(nil).Pause), arg0)
+}
+
+// Restart mocks base method
+func (m *MockController) Restart(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restart", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restart indicates an expected call of Restart
+func (mr *MockControllerMockRecorder) Restart(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restart", reflect.TypeOf((*MockController)(nil).Restart), arg0)
+}
+
+// Resume mocks base method
+func (m *MockController) Resume(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resume indicates an expected call of Resume
+func (mr *MockControllerMockRecorder) Resume(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockController)(nil).Resume), arg0)
+}
+
 // SetProperty mocks base method
 func (m *MockController) SetProperty(arg0, arg1 string, arg2 interface{}) error {
 	m.ctrl.T.Helper()
@@ -133,3 +246,32 @@ func (mr *MockControllerMockRecorder) Start(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockController)(nil).Start), arg0)
 }
+
+// SubmitDialog mocks base method
+func (m *MockController) SubmitDialog(arg0 string, arg1 int, arg2 map[string]interface{}) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitDialog", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitDialog indicates an expected call of SubmitDialog
+func (mr *MockControllerMockRecorder) SubmitDialog(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitDialog", reflect.TypeOf((*MockController)(nil).SubmitDialog), arg0, arg1, arg2)
+}
+
+// ValidateAction mocks base method
+func (m *MockController) ValidateAction(arg0 string, arg1 int, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateAction indicates an expected call of ValidateAction
+func (mr *MockControllerMockRecorder) ValidateAction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAction", reflect.TypeOf((*MockController)(nil).ValidateAction), arg0, arg1, arg2)
+}
@@ -59,6 +59,20 @@ func (mr *MockFlowMockRecorder) Length() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Length", reflect.TypeOf((*MockFlow)(nil).Length))
 }
 
+// Next mocks base method
+func (m *MockFlow) Next(arg0 int, arg1 interface{}, arg2 map[string]interface{}) int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Next", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Next indicates an expected call of Next
+func (mr *MockFlowMockRecorder) Next(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockFlow)(nil).Next), arg0, arg1, arg2)
+}
+
 // Step mocks base method
 func (m *MockFlow) Step(arg0 int) steps.Step {
 	m.ctrl.T.Helper()
@@ -61,6 +61,21 @@ func (mr *MockStoreMockRecorder) GetCurrentStep(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentStep", reflect.TypeOf((*MockStore)(nil).GetCurrentStep), arg0)
 }
 
+// GetHistory mocks base method
+func (m *MockStore) GetHistory(arg0 string) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", arg0)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory
+func (mr *MockStoreMockRecorder) GetHistory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockStore)(nil).GetHistory), arg0)
+}
+
 // GetPostID mocks base method
 func (m *MockStore) GetPostID(arg0, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -76,6 +91,66 @@ func (mr *MockStoreMockRecorder) GetPostID(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostID", reflect.TypeOf((*MockStore)(nil).GetPostID), arg0, arg1)
 }
 
+// GetProperties mocks base method
+func (m *MockStore) GetProperties(arg0 string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProperties", arg0)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProperties indicates an expected call of GetProperties
+func (mr *MockStoreMockRecorder) GetProperties(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProperties", reflect.TypeOf((*MockStore)(nil).GetProperties), arg0)
+}
+
+// GetUpdatedAt mocks base method
+func (m *MockStore) GetUpdatedAt(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUpdatedAt", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpdatedAt indicates an expected call of GetUpdatedAt
+func (mr *MockStoreMockRecorder) GetUpdatedAt(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpdatedAt", reflect.TypeOf((*MockStore)(nil).GetUpdatedAt), arg0)
+}
+
+// IsPaused mocks base method
+func (m *MockStore) IsPaused(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPaused", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsPaused indicates an expected call of IsPaused
+func (mr *MockStoreMockRecorder) IsPaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPaused", reflect.TypeOf((*MockStore)(nil).IsPaused), arg0)
+}
+
+// ListUserIDs mocks base method
+func (m *MockStore) ListUserIDs() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserIDs")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserIDs indicates an expected call of ListUserIDs
+func (mr *MockStoreMockRecorder) ListUserIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserIDs", reflect.TypeOf((*MockStore)(nil).ListUserIDs))
+}
+
 // RemovePostID mocks base method
 func (m *MockStore) RemovePostID(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -104,6 +179,34 @@ func (mr *MockStoreMockRecorder) SetCurrentStep(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCurrentStep", reflect.TypeOf((*MockStore)(nil).SetCurrentStep), arg0, arg1)
 }
 
+// SetHistory mocks base method
+func (m *MockStore) SetHistory(arg0 string, arg1 []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHistory indicates an expected call of SetHistory
+func (mr *MockStoreMockRecorder) SetHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHistory", reflect.TypeOf((*MockStore)(nil).SetHistory), arg0, arg1)
+}
+
+// SetPaused mocks base method
+func (m *MockStore) SetPaused(arg0 string, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPaused", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPaused indicates an expected call of SetPaused
+func (mr *MockStoreMockRecorder) SetPaused(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPaused", reflect.TypeOf((*MockStore)(nil).SetPaused), arg0, arg1)
+}
+
 // SetPostID mocks base method
 func (m *MockStore) SetPostID(arg0, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -117,3 +220,31 @@ func (mr *MockStoreMockRecorder) SetPostID(arg0, arg1, arg2 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPostID", reflect.TypeOf((*MockStore)(nil).SetPostID), arg0, arg1, arg2)
 }
+
+// SetProperties mocks base method
+func (m *MockStore) SetProperties(arg0 string, arg1 map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProperties", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProperties indicates an expected call of SetProperties
+func (mr *MockStoreMockRecorder) SetProperties(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProperties", reflect.TypeOf((*MockStore)(nil).SetProperties), arg0, arg1)
+}
+
+// SetUpdatedAt mocks base method
+func (m *MockStore) SetUpdatedAt(arg0 string, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUpdatedAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUpdatedAt indicates an expected call of SetUpdatedAt
+func (mr *MockStoreMockRecorder) SetUpdatedAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUpdatedAt", reflect.TypeOf((*MockStore)(nil).SetUpdatedAt), arg0, arg1)
+}
@@ -0,0 +1,117 @@
+package flow
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
+)
+
+// Registry holds several flows of a plugin, each identified by an ID. The state of every flow is
+// stored separately for each user, so a user can go through several flows at the same time, e.g.
+// an onboarding flow and a setup wizard.
+type Registry interface {
+	// Register creates the controller of a flow. Its state is stored under its own key prefix,
+	// derived from a hash of the ID so that the keys fit in the KV store whatever the length of the
+	// ID, and its ID is added to the context of every action it posts.
+	Register(id string, flow Flow, propertyStore PropertyStore, options ...ControllerOption) (Controller, error)
+	// Get returns the controller of the flow with the given ID, or nil if there is none.
+	Get(id string) Controller
+	// Controllers returns the controllers of all flows, in the order they were registered.
+	Controllers() []Controller
+	// ActiveFlows returns the IDs of the flows the user is going through, including paused ones.
+	ActiveFlows(userID string) ([]string, error)
+}
+
+// validFlowID matches the IDs flows can be registered with. IDs are added to the context of the
+// actions of the flows, and may not contain the "-" separating the parts of the KV keys.
+var validFlowID = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+type registry struct {
+	poster      poster.Poster
+	logger      logger.Logger
+	pluginURL   string
+	client      pluginapi.Client
+	keyPrefix   string
+	controllers []Controller
+	byID        map[string]Controller
+}
+
+// NewRegistry creates a new Registry. The state of each flow is stored under keyPrefix followed by
+// "-" and a hash of the flow ID, which may only contain letters, digits and underscores. keyPrefix
+// must therefore not be longer than MaxKeyPrefixLength minus 7 characters, e.g. "flow".
+func NewRegistry(p poster.Poster, l logger.Logger, pluginURL string, apiClient pluginapi.Client, keyPrefix string) Registry {
+	return &registry{
+		poster:    p,
+		logger:    l,
+		pluginURL: pluginURL,
+		client:    apiClient,
+		keyPrefix: keyPrefix,
+		byID:      map[string]Controller{},
+	}
+}
+
+func (r *registry) Register(id string, flow Flow, propertyStore PropertyStore, options ...ControllerOption) (Controller, error) {
+	if !validFlowID.MatchString(id) {
+		return nil, errors.Errorf("invalid flow id %q: only letters, digits and underscores are allowed", id)
+	}
+
+	if _, ok := r.byID[id]; ok {
+		return nil, errors.Errorf("flow %s is already registered", id)
+	}
+
+	keyPrefix := flowKeyPrefix(r.keyPrefix, id)
+	for _, fc := range r.controllers {
+		if flowKeyPrefix(r.keyPrefix, fc.GetFlowID()) == keyPrefix {
+			return nil, errors.Errorf("flows %s and %s have the same key prefix %q", fc.GetFlowID(), id, keyPrefix)
+		}
+	}
+
+	options = append(options, WithFlowID(id))
+	store, err := NewFlowStore(r.client, keyPrefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create the store of flow %s", id)
+	}
+	fc := NewFlowController(r.poster, r.logger, r.pluginURL, flow, store, propertyStore, options...)
+
+	r.controllers = append(r.controllers, fc)
+	r.byID[id] = fc
+
+	return fc, nil
+}
+
+// flowKeyPrefix returns the key prefix of a flow, e.g. "flow-1a2b3c". Flow IDs can be of any
+// length, so they are hashed to keep the keys of the flow within the limit of the KV store.
+func flowKeyPrefix(keyPrefix, id string) string {
+	hash := sha256.Sum256([]byte(id))
+	return fmt.Sprintf("%s-%x", keyPrefix, hash[:3])
+}
+
+func (r *registry) Get(id string) Controller {
+	return r.byID[id]
+}
+
+func (r *registry) Controllers() []Controller {
+	return r.controllers
+}
+
+func (r *registry) ActiveFlows(userID string) ([]string, error) {
+	ids := []string{}
+	for _, fc := range r.controllers {
+		_, index, err := fc.GetCurrentStep(userID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get current step of flow %s", fc.GetFlowID())
+		}
+
+		if index != 0 {
+			ids = append(ids, fc.GetFlowID())
+		}
+	}
+
+	return ids, nil
+}
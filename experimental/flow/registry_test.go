@@ -0,0 +1,254 @@
+package flow
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
+	"github.com/mattermost/mattermost-plugin-api/experimental/flow/steps"
+)
+
+type fakePoster struct {
+	poster.Poster
+	attachments []*model.SlackAttachment
+	postIDs     []string
+	updated     []*model.Post
+	deleted     []string
+}
+
+func (p *fakePoster) DMWithAttachments(userID string, attachments ...*model.SlackAttachment) (string, error) {
+	postID := model.NewId()
+	p.attachments = append(p.attachments, attachments...)
+	p.postIDs = append(p.postIDs, postID)
+	return postID, nil
+}
+
+func (p *fakePoster) UpdatePost(post *model.Post) error {
+	p.updated = append(p.updated, post)
+	return nil
+}
+
+func (p *fakePoster) DeletePost(postID string) error {
+	p.deleted = append(p.deleted, postID)
+	return nil
+}
+
+type fakePropertyStore struct{}
+
+func (fakePropertyStore) SetProperty(userID, propertyName string, value interface{}) error {
+	return nil
+}
+
+func setupKV() *plugintest.API {
+	var mu sync.Mutex
+	kv := map[string][]byte{}
+
+	// The server rejects keys longer than the limit.
+	checkKey := func(key string) *model.AppError {
+		if utf8.RuneCountInString(key) > model.KEY_VALUE_KEY_MAX_RUNES {
+			return model.NewAppError("KVSetWithOptions", "key too long", nil, key, http.StatusBadRequest)
+		}
+		return nil
+	}
+
+	api := &plugintest.API{}
+	api.On("KVGet", mock.AnythingOfType("string")).Return(
+		func(key string) []byte {
+			mu.Lock()
+			defer mu.Unlock()
+			return kv[key]
+		},
+		checkKey,
+	)
+	api.On("KVList", mock.AnythingOfType("int"), mock.AnythingOfType("int")).Return(
+		func(page, perPage int) []string {
+			mu.Lock()
+			defer mu.Unlock()
+			keys := []string{}
+			for key := range kv {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if page*perPage >= len(keys) {
+				return []string{}
+			}
+			keys = keys[page*perPage:]
+			if len(keys) > perPage {
+				keys = keys[:perPage]
+			}
+			return keys
+		},
+		nil,
+	)
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(
+		func(key string, value []byte, options model.PluginKVSetOptions) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if checkKey(key) != nil {
+				return false
+			}
+			if value == nil {
+				delete(kv, key)
+			} else {
+				kv[key] = value
+			}
+			return true
+		},
+		func(key string, value []byte, options model.PluginKVSetOptions) *model.AppError {
+			return checkKey(key)
+		},
+	)
+
+	return api
+}
+
+func newTestFlow() Flow {
+	return NewFlow([]steps.Step{
+		steps.NewSimpleStep("title", "message", "property", "yes", "no", "yes", "no", 0, 0),
+		steps.NewSimpleStep("title", "message", "another_property", "yes", "no", "yes", "no", 0, 0),
+	}, "/flow", nil)
+}
+
+func TestRegistry(t *testing.T) {
+	userID := model.NewId()
+
+	t.Run("register", func(t *testing.T) {
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		r := NewRegistry(&fakePoster{}, logger.NewNilLogger(), "/plugins/test", *client, "flow")
+
+		fc, err := r.Register("onboarding", newTestFlow(), fakePropertyStore{})
+		require.NoError(t, err)
+		assert.Equal(t, "onboarding", fc.GetFlowID())
+		assert.Equal(t, fc, r.Get("onboarding"))
+		assert.Nil(t, r.Get("setup"))
+
+		_, err = r.Register("onboarding", newTestFlow(), fakePropertyStore{})
+		require.Error(t, err)
+
+		_, err = r.Register("", newTestFlow(), fakePropertyStore{})
+		require.Error(t, err)
+
+		_, err = r.Register("onboarding-step", newTestFlow(), fakePropertyStore{})
+		require.Error(t, err)
+	})
+
+	t.Run("long flow id", func(t *testing.T) {
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		r := NewRegistry(&fakePoster{}, logger.NewNilLogger(), "/plugins/test", *client, "flow")
+
+		fc, err := r.Register("a_rather_long_flow_id_for_the_setup_wizard", newTestFlow(), fakePropertyStore{})
+		require.NoError(t, err)
+		require.NoError(t, fc.Start(userID))
+		require.NoError(t, fc.NextStep(userID, 1, "true"))
+		require.NoError(t, fc.Pause(userID))
+
+		active, err := r.ActiveFlows(userID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a_rather_long_flow_id_for_the_setup_wizard"}, active)
+	})
+
+	t.Run("key prefix length", func(t *testing.T) {
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		_, err := NewFlowStore(*client, strings.Repeat("f", MaxKeyPrefixLength+1))
+		require.Error(t, err)
+
+		r := NewRegistry(&fakePoster{}, logger.NewNilLogger(), "/plugins/test", *client, "a_long_prefix")
+		_, err = r.Register("onboarding", newTestFlow(), fakePropertyStore{})
+		require.Error(t, err)
+	})
+
+	t.Run("colliding key prefixes", func(t *testing.T) {
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		x, err := NewFlowStore(*client, "flow-x")
+		require.NoError(t, err)
+		xStep, err := NewFlowStore(*client, "flow-x-step")
+		require.NoError(t, err)
+
+		otherUserID := model.NewId()
+		require.NoError(t, x.SetCurrentStep(userID, 1))
+		require.NoError(t, xStep.SetCurrentStep(otherUserID, 1))
+
+		userIDs, err := x.ListUserIDs()
+		require.NoError(t, err)
+		assert.Equal(t, []string{userID}, userIDs)
+
+		userIDs, err = xStep.ListUserIDs()
+		require.NoError(t, err)
+		assert.Equal(t, []string{otherUserID}, userIDs)
+	})
+
+	t.Run("flows are isolated", func(t *testing.T) {
+		p := &fakePoster{}
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		r := NewRegistry(p, logger.NewNilLogger(), "/plugins/test", *client, "flow")
+
+		onboarding, err := r.Register("onboarding", newTestFlow(), fakePropertyStore{})
+		require.NoError(t, err)
+		setup, err := r.Register("setup", newTestFlow(), fakePropertyStore{})
+		require.NoError(t, err)
+
+		require.NoError(t, onboarding.Start(userID))
+		require.NoError(t, setup.Start(userID))
+		require.NoError(t, onboarding.NextStep(userID, 1, "true"))
+
+		_, index, err := onboarding.GetCurrentStep(userID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, index)
+
+		_, index, err = setup.GetCurrentStep(userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, index)
+
+		active, err := r.ActiveFlows(userID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"onboarding", "setup"}, active)
+
+		require.Len(t, p.attachments, 3)
+		for _, action := range p.attachments[1].Actions {
+			assert.Equal(t, "setup", action.Integration.Context[steps.ContextFlowIDKey])
+		}
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		p := &fakePoster{}
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		r := NewRegistry(p, logger.NewNilLogger(), "/plugins/test", *client, "flow")
+
+		fc, err := r.Register("onboarding", newTestFlow(), fakePropertyStore{})
+		require.NoError(t, err)
+
+		require.Error(t, fc.Pause(userID))
+		require.NoError(t, fc.Start(userID))
+		require.NoError(t, fc.Pause(userID))
+		assert.Len(t, p.deleted, 1)
+
+		paused, err := fc.IsPaused(userID)
+		require.NoError(t, err)
+		assert.True(t, paused)
+
+		require.NoError(t, fc.NextStep(userID, 1, "true"))
+		_, index, err := fc.GetCurrentStep(userID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, index)
+
+		require.NoError(t, fc.Resume(userID))
+		assert.Len(t, p.attachments, 2)
+		require.Error(t, fc.Resume(userID))
+
+		paused, err = fc.IsPaused(userID)
+		require.NoError(t, err)
+		assert.False(t, paused)
+	})
+}
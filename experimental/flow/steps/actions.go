@@ -0,0 +1,69 @@
+package steps
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+func newButtonAction(name, flowHandler, propertyName string, i int, value interface{}) *model.PostAction {
+	rawValue, _ := json.Marshal(value)
+	stepValue, _ := json.Marshal(i)
+
+	return &model.PostAction{
+		Name: name,
+		Integration: &model.PostActionIntegration{
+			URL: flowHandler,
+			Context: map[string]interface{}{
+				ContextPropertyKey:    propertyName,
+				ContextButtonValueKey: string(rawValue),
+				ContextStepKey:        string(stepValue),
+			},
+		},
+	}
+}
+
+func newSelectAction(name, flowHandler, propertyName string, i int, options []*model.PostActionOptions, dataSource string) *model.PostAction {
+	stepValue, _ := json.Marshal(i)
+
+	return &model.PostAction{
+		Name:       name,
+		Type:       model.POST_ACTION_TYPE_SELECT,
+		Options:    options,
+		DataSource: dataSource,
+		Integration: &model.PostActionIntegration{
+			URL: flowHandler,
+			Context: map[string]interface{}{
+				ContextPropertyKey: propertyName,
+				ContextStepKey:     string(stepValue),
+			},
+		},
+	}
+}
+
+// NewNavigationAction creates a button moving the flow back, restarting it or resuming it,
+// depending on navigation being NavigationBack, NavigationRestart or NavigationResume.
+func NewNavigationAction(name, flowHandler string, i int, navigation string) *model.PostAction {
+	stepValue, _ := json.Marshal(i)
+
+	return &model.PostAction{
+		Name: name,
+		Integration: &model.PostActionIntegration{
+			URL: flowHandler,
+			Context: map[string]interface{}{
+				ContextNavigationKey: navigation,
+				ContextStepKey:       string(stepValue),
+			},
+		},
+	}
+}
+
+func optionText(options []*model.PostActionOptions, value string) string {
+	for _, o := range options {
+		if o.Value == value {
+			return o.Text
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,58 @@
+package steps
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+/*
+NewDateTimeStep creates a step asking the user to pick a date or a time among a list of slots.
+The selected slot is stored in the property formatted as RFC 3339, see ParseDateTimeValue.
+
+- start: The first slot offered.
+
+- count: The number of slots offered.
+
+- interval: The time between two slots, e.g. 24 hours for a date picker or 30 minutes for a time picker.
+
+- layout: The layout used to display the slots, as accepted by time.Format.
+*/
+func NewDateTimeStep(
+	title,
+	message,
+	propertyName,
+	placeholder string,
+	start time.Time,
+	count int,
+	interval time.Duration,
+	layout string,
+) Step {
+	options := make([]*model.PostActionOptions, 0, count)
+	for k := 0; k < count; k++ {
+		slot := start.Add(time.Duration(k) * interval)
+		options = append(options, &model.PostActionOptions{
+			Text:  slot.Format(layout),
+			Value: slot.Format(time.RFC3339),
+		})
+	}
+
+	return &optionStep{
+		Title:        title,
+		Message:      message,
+		PropertyName: propertyName,
+		Placeholder:  placeholder,
+		Options:      options,
+	}
+}
+
+// ParseDateTimeValue decodes the value set by a date time step.
+func ParseDateTimeValue(rawValue interface{}) (time.Time, error) {
+	raw, ok := rawValue.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected date time value %v", rawValue)
+	}
+
+	return time.Parse(time.RFC3339, raw)
+}
@@ -0,0 +1,129 @@
+package steps
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+type dialogStep struct {
+	Title        string
+	Message      string
+	PropertyName string
+	ButtonLabel  string
+	SubmitLabel  string
+	Elements     []model.DialogElement
+	validate     func(submission map[string]interface{}) map[string]string
+}
+
+// NewDialogStep creates a step with a button opening an interactive dialog with the given
+// elements, e.g. text, select, radio or bool elements. The submission, a map from element name to
+// value, is stored in the property. validate may be nil.
+func NewDialogStep(
+	title,
+	message,
+	propertyName,
+	buttonLabel,
+	submitLabel string,
+	elements []model.DialogElement,
+	validate func(submission map[string]interface{}) map[string]string,
+) Step {
+	return &dialogStep{
+		Title:        title,
+		Message:      message,
+		PropertyName: propertyName,
+		ButtonLabel:  buttonLabel,
+		SubmitLabel:  submitLabel,
+		Elements:     elements,
+		validate:     validate,
+	}
+}
+
+func (s *dialogStep) PostSlackAttachment(flowHandler string, i int) *model.SlackAttachment {
+	stepValue, _ := json.Marshal(i)
+
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     s.Message,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, s.Message),
+		Actions: []*model.PostAction{{
+			Name:  s.ButtonLabel,
+			Style: "primary",
+			Integration: &model.PostActionIntegration{
+				URL: flowHandler,
+				Context: map[string]interface{}{
+					ContextPropertyKey: s.PropertyName,
+					ContextStepKey:     string(stepValue),
+					ContextDialogKey:   true,
+				},
+			},
+		}},
+	}
+
+	return &sa
+}
+
+func (s *dialogStep) ResponseSlackAttachment(value interface{}) *model.SlackAttachment {
+	submission, _ := value.(map[string]interface{})
+
+	lines := []string{}
+	for _, element := range s.Elements {
+		v, ok := submission[element.Name]
+		if !ok || v == nil || v == "" {
+			continue
+		}
+
+		text := fmt.Sprint(v)
+		if element.Type == "select" || element.Type == "radio" {
+			text = optionText(element.Options, text)
+		}
+		lines = append(lines, fmt.Sprintf("%s: **%s**", element.DisplayName, text))
+	}
+	text := strings.Join(lines, "\n")
+
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     text,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, text),
+		Actions:  []*model.PostAction{},
+	}
+
+	return &sa
+}
+
+func (s *dialogStep) Dialog() model.Dialog {
+	return model.Dialog{
+		Title:            s.Title,
+		IntroductionText: s.Message,
+		Elements:         s.Elements,
+		SubmitLabel:      s.SubmitLabel,
+	}
+}
+
+func (s *dialogStep) ValidateSubmission(submission map[string]interface{}) map[string]string {
+	if s.validate == nil {
+		return nil
+	}
+
+	return s.validate(submission)
+}
+
+func (s *dialogStep) GetPropertyName() string {
+	return s.PropertyName
+}
+
+func (s *dialogStep) ShouldSkip(value interface{}) int {
+	return 0
+}
+
+func (s *dialogStep) IsEmpty() bool {
+	return false
+}
+
+func (*dialogStep) GetFreetextFetcher() freetextfetcher.FreetextFetcher {
+	return nil
+}
@@ -17,30 +17,40 @@ type freetextStep struct {
 	FreetextFetcher freetextfetcher.FreetextFetcher
 }
 
+// NewFreetextStep creates a step fetching a free text reply of the user. Options configure the
+// underlying fetcher, e.g. freetextfetcher.WithTTL. baseURL must be unique among the fetchers of
+// the manager.
 func NewFreetextStep(
 	title,
 	message,
 	propertyName,
 	baseURL string,
-	store freetextfetcher.FreetextStore,
+	manager freetextfetcher.Manager,
 	validate func(string) string,
 	r *mux.Router,
 	p poster.Poster,
-) Step {
-	return &freetextStep{
-		Title:        title,
-		Message:      message,
-		PropertyName: propertyName,
-		FreetextFetcher: freetextfetcher.NewFreetextFetcher(
-			baseURL,
-			store,
-			validate,
-			nil,
-			nil,
-			r,
-			p,
-		),
+	options ...freetextfetcher.Option,
+) (Step, error) {
+	ftf, err := freetextfetcher.NewFreetextFetcher(
+		baseURL,
+		manager,
+		validate,
+		nil,
+		nil,
+		r,
+		p,
+		options...,
+	)
+	if err != nil {
+		return nil, err
 	}
+
+	return &freetextStep{
+		Title:           title,
+		Message:         message,
+		PropertyName:    propertyName,
+		FreetextFetcher: ftf,
+	}, nil
 }
 
 func (s *freetextStep) PostSlackAttachment(flowHandler string, i int) *model.SlackAttachment {
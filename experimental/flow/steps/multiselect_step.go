@@ -0,0 +1,139 @@
+package steps
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+type multiSelectStep struct {
+	Title             string
+	Message           string
+	PropertyName      string
+	DoneButtonMessage string
+	Options           []*model.PostActionOptions
+}
+
+// NewMultiSelectStep creates a step asking the user to toggle any number of the given options,
+// and confirm with the done button. The property is set to the JSON encoded list of the
+// selected option values.
+func NewMultiSelectStep(
+	title,
+	message,
+	propertyName,
+	doneButtonMessage string,
+	options []*model.PostActionOptions,
+) Step {
+	return &multiSelectStep{
+		Title:             title,
+		Message:           message,
+		PropertyName:      propertyName,
+		DoneButtonMessage: doneButtonMessage,
+		Options:           options,
+	}
+}
+
+func (s *multiSelectStep) PostSlackAttachment(flowHandler string, i int) *model.SlackAttachment {
+	return s.render(flowHandler, i, []string{})
+}
+
+func (s *multiSelectStep) PartialSlackAttachment(flowHandler string, i int, value interface{}) *model.SlackAttachment {
+	return s.render(flowHandler, i, ParseMultiSelectValue(value))
+}
+
+func (s *multiSelectStep) render(flowHandler string, i int, selected []string) *model.SlackAttachment {
+	actions := []*model.PostAction{}
+	for _, o := range s.Options {
+		name := o.Text
+		toggled := make([]string, 0, len(selected)+1)
+		isSelected := false
+		for _, v := range selected {
+			if v == o.Value {
+				isSelected = true
+				continue
+			}
+			toggled = append(toggled, v)
+		}
+		if isSelected {
+			name = "✓ " + name
+		} else {
+			toggled = append(toggled, o.Value)
+		}
+
+		action := newButtonAction(name, flowHandler, s.PropertyName, i, toggled)
+		action.Integration.Context[ContextPartialKey] = true
+		actions = append(actions, action)
+	}
+
+	done := newButtonAction(s.DoneButtonMessage, flowHandler, s.PropertyName, i, selected)
+	done.Style = "primary"
+	actions = append(actions, done)
+
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     s.Message,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, s.Message),
+		Actions:  actions,
+	}
+
+	return &sa
+}
+
+func (s *multiSelectStep) ResponseSlackAttachment(value interface{}) *model.SlackAttachment {
+	selected := []string{}
+	for _, v := range ParseMultiSelectValue(value) {
+		selected = append(selected, "**"+optionText(s.Options, v)+"**")
+	}
+
+	text := "Nothing selected"
+	if len(selected) > 0 {
+		text = "Selected: " + strings.Join(selected, ", ")
+	}
+
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     text,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, text),
+		Actions:  []*model.PostAction{},
+	}
+
+	return &sa
+}
+
+func (s *multiSelectStep) GetPropertyName() string {
+	return s.PropertyName
+}
+
+func (s *multiSelectStep) ShouldSkip(value interface{}) int {
+	return 0
+}
+
+func (s *multiSelectStep) IsEmpty() bool {
+	return false
+}
+
+func (*multiSelectStep) GetFreetextFetcher() freetextfetcher.FreetextFetcher {
+	return nil
+}
+
+// ParseMultiSelectValue decodes the value set by a multi-select step into the list of selected
+// option values.
+func ParseMultiSelectValue(rawValue interface{}) []string {
+	value := []string{}
+
+	raw, ok := rawValue.(string)
+	if !ok {
+		return value
+	}
+
+	err := json.Unmarshal([]byte(raw), &value)
+	if err != nil {
+		return []string{}
+	}
+
+	return value
+}
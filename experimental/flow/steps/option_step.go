@@ -0,0 +1,75 @@
+package steps
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+type optionStep struct {
+	Title        string
+	Message      string
+	PropertyName string
+	Placeholder  string
+	Options      []*model.PostActionOptions
+}
+
+// NewOptionStep creates a step asking the user to select one of the given options. The value
+// of the selected option is stored in the property.
+func NewOptionStep(
+	title,
+	message,
+	propertyName,
+	placeholder string,
+	options []*model.PostActionOptions,
+) Step {
+	return &optionStep{
+		Title:        title,
+		Message:      message,
+		PropertyName: propertyName,
+		Placeholder:  placeholder,
+		Options:      options,
+	}
+}
+
+func (s *optionStep) PostSlackAttachment(flowHandler string, i int) *model.SlackAttachment {
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     s.Message,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, s.Message),
+		Actions:  []*model.PostAction{newSelectAction(s.Placeholder, flowHandler, s.PropertyName, i, s.Options, "")},
+	}
+
+	return &sa
+}
+
+func (s *optionStep) ResponseSlackAttachment(value interface{}) *model.SlackAttachment {
+	text := fmt.Sprintf("Selected: **%s**", optionText(s.Options, fmt.Sprint(value)))
+
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     text,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, text),
+		Actions:  []*model.PostAction{},
+	}
+
+	return &sa
+}
+
+func (s *optionStep) GetPropertyName() string {
+	return s.PropertyName
+}
+
+func (s *optionStep) ShouldSkip(value interface{}) int {
+	return 0
+}
+
+func (s *optionStep) IsEmpty() bool {
+	return false
+}
+
+func (*optionStep) GetFreetextFetcher() freetextfetcher.FreetextFetcher {
+	return nil
+}
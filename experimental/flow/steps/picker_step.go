@@ -0,0 +1,87 @@
+package steps
+
+import (
+	"fmt"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+const (
+	dataSourceUsers    = "users"
+	dataSourceChannels = "channels"
+)
+
+type pickerStep struct {
+	Title           string
+	Message         string
+	PropertyName    string
+	Placeholder     string
+	ResponseMessage string
+	DataSource      string
+}
+
+// NewUserPickerStep creates a step asking the user to select a user. The ID of the selected
+// user is stored in the property.
+func NewUserPickerStep(title, message, propertyName, placeholder, responseMessage string) Step {
+	return &pickerStep{
+		Title:           title,
+		Message:         message,
+		PropertyName:    propertyName,
+		Placeholder:     placeholder,
+		ResponseMessage: responseMessage,
+		DataSource:      dataSourceUsers,
+	}
+}
+
+// NewChannelPickerStep creates a step asking the user to select a channel. The ID of the
+// selected channel is stored in the property.
+func NewChannelPickerStep(title, message, propertyName, placeholder, responseMessage string) Step {
+	return &pickerStep{
+		Title:           title,
+		Message:         message,
+		PropertyName:    propertyName,
+		Placeholder:     placeholder,
+		ResponseMessage: responseMessage,
+		DataSource:      dataSourceChannels,
+	}
+}
+
+func (s *pickerStep) PostSlackAttachment(flowHandler string, i int) *model.SlackAttachment {
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     s.Message,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, s.Message),
+		Actions:  []*model.PostAction{newSelectAction(s.Placeholder, flowHandler, s.PropertyName, i, nil, s.DataSource)},
+	}
+
+	return &sa
+}
+
+func (s *pickerStep) ResponseSlackAttachment(value interface{}) *model.SlackAttachment {
+	sa := model.SlackAttachment{
+		Title:    s.Title,
+		Text:     s.ResponseMessage,
+		Fallback: fmt.Sprintf("%s: %s", s.Title, s.ResponseMessage),
+		Actions:  []*model.PostAction{},
+	}
+
+	return &sa
+}
+
+func (s *pickerStep) GetPropertyName() string {
+	return s.PropertyName
+}
+
+func (s *pickerStep) ShouldSkip(value interface{}) int {
+	return 0
+}
+
+func (s *pickerStep) IsEmpty() bool {
+	return false
+}
+
+func (*pickerStep) GetFreetextFetcher() freetextfetcher.FreetextFetcher {
+	return nil
+}
@@ -11,6 +11,14 @@ const (
 	ContextButtonValueKey = "button_value"
 	ContextOptionValueKey = "selected_option"
 	ContextStepKey        = "step"
+	ContextPartialKey     = "partial"
+	ContextNavigationKey  = "navigation"
+	ContextFlowIDKey      = "flow_id"
+	ContextDialogKey      = "dialog"
+
+	NavigationBack    = "back"
+	NavigationRestart = "restart"
+	NavigationResume  = "resume"
 )
 
 type Step interface {
@@ -21,3 +29,20 @@ type Step interface {
 	IsEmpty() bool
 	GetFreetextFetcher() freetextfetcher.FreetextFetcher
 }
+
+// PartialStep is implemented by steps whose value is built across several actions, e.g. a
+// multi-select. Actions flagged with ContextPartialKey update the step post instead of moving
+// the flow forward.
+type PartialStep interface {
+	PartialSlackAttachment(flowHandler string, i int, value interface{}) *model.SlackAttachment
+}
+
+// DialogStep is implemented by steps whose value is collected through an interactive dialog.
+// Actions flagged with ContextDialogKey open the dialog, and the submission becomes the value of
+// the step.
+type DialogStep interface {
+	Dialog() model.Dialog
+	// ValidateSubmission returns the errors by element name, on top of the constraints of the
+	// dialog elements.
+	ValidateSubmission(submission map[string]interface{}) map[string]string
+}
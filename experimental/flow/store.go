@@ -1,7 +1,13 @@
 package flow
 
 import (
+	"crypto/sha256"
+	"encoding/base32"
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 )
@@ -17,18 +23,39 @@ type Store interface {
 	GetCurrentStep(userID string) (int, error)
 	SetCurrentStep(userID string, step int) error
 	DeleteCurrentStep(userID string) error
+	GetHistory(userID string) ([]int, error)
+	SetHistory(userID string, history []int) error
+	GetProperties(userID string) (map[string]interface{}, error)
+	SetProperties(userID string, properties map[string]interface{}) error
+	IsPaused(userID string) (bool, error)
+	SetPaused(userID string, paused bool) error
+	GetUpdatedAt(userID string) (int64, error)
+	SetUpdatedAt(userID string, updatedAt int64) error
+	ListUserIDs() ([]string, error)
 }
 
+const listKeysPerPage = 1000
+
+// MaxKeyPrefixLength is the longest key prefix whose keys fit in the KV store, which limits keys
+// to 50 characters. The longest key is prefix + "-updated-" + user ID.
+const MaxKeyPrefixLength = model.KEY_VALUE_KEY_MAX_RUNES - len("-updated-") - 26
+
 type flowStore struct {
 	client    pluginapi.Client
 	keyPrefix string
 }
 
-func NewFlowStore(apiClient pluginapi.Client, keyPrefix string) Store {
+// NewFlowStore creates a Store keeping the state of a flow in the KV store, under keys starting
+// with keyPrefix. keyPrefix must not be longer than MaxKeyPrefixLength.
+func NewFlowStore(apiClient pluginapi.Client, keyPrefix string) (Store, error) {
+	if len(keyPrefix) > MaxKeyPrefixLength {
+		return nil, fmt.Errorf("key prefix %q is longer than %d characters", keyPrefix, MaxKeyPrefixLength)
+	}
+
 	return &flowStore{
 		client:    apiClient,
 		keyPrefix: keyPrefix,
-	}
+	}, nil
 }
 
 func (fs *flowStore) SetPostID(userID, propertyName, postID string) error {
@@ -79,10 +106,151 @@ func (fs *flowStore) DeleteCurrentStep(userID string) error {
 	return fs.client.KV.Delete(fs.getStepKey(userID))
 }
 
+func (fs *flowStore) GetHistory(userID string) ([]int, error) {
+	var history []int
+	err := fs.client.KV.Get(fs.getHistoryKey(userID), &history)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (fs *flowStore) SetHistory(userID string, history []int) error {
+	if len(history) == 0 {
+		return fs.client.KV.Delete(fs.getHistoryKey(userID))
+	}
+
+	ok, err := fs.client.KV.Set(fs.getHistoryKey(userID), history)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("value not set without errors")
+	}
+	return nil
+}
+
+func (fs *flowStore) GetProperties(userID string) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	err := fs.client.KV.Get(fs.getPropertiesKey(userID), &properties)
+	if err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+func (fs *flowStore) SetProperties(userID string, properties map[string]interface{}) error {
+	if len(properties) == 0 {
+		return fs.client.KV.Delete(fs.getPropertiesKey(userID))
+	}
+
+	ok, err := fs.client.KV.Set(fs.getPropertiesKey(userID), properties)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("value not set without errors")
+	}
+	return nil
+}
+
+func (fs *flowStore) IsPaused(userID string) (bool, error) {
+	var paused bool
+	err := fs.client.KV.Get(fs.getPausedKey(userID), &paused)
+	if err != nil {
+		return false, err
+	}
+	return paused, nil
+}
+
+func (fs *flowStore) SetPaused(userID string, paused bool) error {
+	if !paused {
+		return fs.client.KV.Delete(fs.getPausedKey(userID))
+	}
+
+	ok, err := fs.client.KV.Set(fs.getPausedKey(userID), paused)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("value not set without errors")
+	}
+	return nil
+}
+
+func (fs *flowStore) GetUpdatedAt(userID string) (int64, error) {
+	var updatedAt int64
+	err := fs.client.KV.Get(fs.getUpdatedAtKey(userID), &updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return updatedAt, nil
+}
+
+func (fs *flowStore) SetUpdatedAt(userID string, updatedAt int64) error {
+	if updatedAt == 0 {
+		return fs.client.KV.Delete(fs.getUpdatedAtKey(userID))
+	}
+
+	ok, err := fs.client.KV.Set(fs.getUpdatedAtKey(userID), updatedAt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("value not set without errors")
+	}
+	return nil
+}
+
+// ListUserIDs returns the IDs of the users going through the flow. Keys whose remainder is not a
+// user ID belong to another flow whose key prefix starts with the one of this flow, and are skipped.
+func (fs *flowStore) ListUserIDs() ([]string, error) {
+	prefix := fs.getStepKey("")
+
+	userIDs := []string{}
+	for page := 0; ; page++ {
+		listed := 0
+		keys, err := fs.client.KV.ListKeys(page, listKeysPerPage, pluginapi.WithChecker(func(key string) (bool, error) {
+			listed++
+			return strings.HasPrefix(key, prefix) && model.IsValidId(strings.TrimPrefix(key, prefix)), nil
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			userIDs = append(userIDs, strings.TrimPrefix(key, prefix))
+		}
+
+		if listed < listKeysPerPage {
+			return userIDs, nil
+		}
+	}
+}
+
+// getPostKey returns the key of the post of a property. Property names have no length limit, so
+// they are hashed with the user ID into a key as long as a user ID.
 func (fs *flowStore) getPostKey(userID, propertyName string) string {
-	return fs.keyPrefix + "-post-" + userID + "-" + propertyName
+	hash := sha256.Sum256([]byte(userID + "-" + propertyName))
+	return fs.keyPrefix + "-post-" + strings.ToLower(base32.StdEncoding.EncodeToString(hash[:]))[:26]
 }
 
 func (fs *flowStore) getStepKey(userID string) string {
 	return fs.keyPrefix + "-step-" + userID
 }
+
+func (fs *flowStore) getHistoryKey(userID string) string {
+	return fs.keyPrefix + "-history-" + userID
+}
+
+func (fs *flowStore) getPropertiesKey(userID string) string {
+	return fs.keyPrefix + "-props-" + userID
+}
+
+func (fs *flowStore) getPausedKey(userID string) string {
+	return fs.keyPrefix + "-paused-" + userID
+}
+
+func (fs *flowStore) getUpdatedAtKey(userID string) string {
+	return fs.keyPrefix + "-updated-" + userID
+}
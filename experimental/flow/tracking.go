@@ -0,0 +1,43 @@
+package flow
+
+import (
+	"github.com/mattermost/mattermost-plugin-api/experimental/telemetry"
+)
+
+// Events tracked by the Controller when a tracker is set with WithTracker.
+const (
+	EventFlowStart        = "flow_start"
+	EventFlowStepComplete = "flow_step_complete"
+	EventFlowComplete     = "flow_complete"
+	EventFlowCancel       = "flow_cancel"
+	EventFlowAbandon      = "flow_abandon"
+)
+
+// WithTracker tracks the start, step completion, completion, cancellation and abandonment of
+// the flow for every user.
+func WithTracker(tracker telemetry.Tracker) ControllerOption {
+	return func(fc *flowController) {
+		fc.tracker = tracker
+	}
+}
+
+func (fc *flowController) track(event, userID string, stepIndex int) {
+	if fc.tracker == nil {
+		return
+	}
+
+	properties := map[string]interface{}{
+		"Step": stepIndex,
+	}
+	if fc.id != "" {
+		properties["FlowID"] = fc.id
+	}
+	if step := fc.flow.Step(stepIndex); step != nil && step.GetPropertyName() != "" {
+		properties["Property"] = step.GetPropertyName()
+	}
+
+	err := fc.tracker.TrackUserEvent(event, userID, properties)
+	if err != nil {
+		fc.Logger.Debugf("error tracking %s, %s", event, err.Error())
+	}
+}
@@ -1,22 +1,30 @@
 package freetextfetcher
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
 	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-server/v5/model"
-	"github.com/mattermost/mattermost-server/v5/plugin"
+	"github.com/pkg/errors"
 )
 
 // FreetextFetcher defines the behavior of free text fetchers
 type FreetextFetcher interface {
-	MessageHasBeenPosted(c *plugin.Context, post *model.Post, api plugin.API, l logger.Logger, botUserID string, pluginURL string)
+	ID() string
 	StartFetching(userID string, payload string)
 	UpdateHooks(validate func(string) string, onFetch func(string, string), onCancel func(string))
 	URL() string
+	// HandleReply processes a message of the user answering the given fetch. It is called by the
+	// Manager.
+	HandleReply(userID, message string, fetch Fetch, l logger.Logger, pluginURL string)
+	// HandleExpiry notifies the fetcher that the given fetch expired. It is called by the Manager.
+	HandleExpiry(userID string, fetch Fetch)
 }
 
 type freetextFetcher struct {
@@ -27,30 +35,54 @@ type freetextFetcher struct {
 	validate func(string) string
 	onFetch  func(string, string)
 	onCancel func(string)
+
+	ttl            time.Duration
+	expiredMessage string
+	maxAttempts    int
+	giveUpMessage  string
+	terminator     string
+	parse          Parser
 }
 
-// NewFreetextFetcher creates a new FreetextFetcher
+// NewFreetextFetcher creates a new FreetextFetcher owned by the given manager. Each user has a
+// queue of pending fetches, answered in the order they were started.
+//
+// The ID of the fetcher is derived from baseURL, so that pending fetches are still answered after
+// the plugin restarts. baseURL must therefore be unique among the fetchers of the manager, and an
+// error is returned otherwise.
 func NewFreetextFetcher(
 	baseURL string,
-	store FreetextStore,
+	m Manager,
 	validate func(string) string,
 	onFetch func(string, string),
 	onCancel func(string),
 	r *mux.Router,
 	p poster.Poster,
-) FreetextFetcher {
+	options ...Option,
+) (FreetextFetcher, error) {
 	ftf := &freetextFetcher{
-		id:       model.NewId(),
+		id:       fetcherID(baseURL),
 		baseURL:  baseURL,
-		store:    store,
+		store:    m.Store(),
 		validate: validate,
 		onFetch:  onFetch,
 		onCancel: onCancel,
 		poster:   p,
 	}
+	for _, option := range options {
+		option(ftf)
+	}
+	err := m.Add(ftf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot add the freetext fetcher for base URL %s", baseURL)
+	}
 	ftf.initHandle(r)
-	ftfManager.ftfList = append(ftfManager.ftfList, ftf)
-	return ftf
+	return ftf, nil
+}
+
+// fetcherID returns a stable ID for the fetcher with the given base URL, safe to use in URLs.
+func fetcherID(baseURL string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(baseURL)))[:26]
 }
 
 func (ftf *freetextFetcher) UpdateHooks(validate func(string) string, onFetch func(string, string), onCancel func(string)) {
@@ -68,46 +100,95 @@ func (ftf *freetextFetcher) UpdateHooks(validate func(string) string, onFetch fu
 }
 
 func (ftf *freetextFetcher) StartFetching(userID, payload string) {
-	_ = ftf.store.StartFetching(userID, ftf.id, payload)
+	fetch := Fetch{
+		ID:        model.NewId(),
+		FetcherID: ftf.id,
+		Payload:   payload,
+	}
+	if ftf.ttl > 0 {
+		fetch.ExpiresAt = model.GetMillis() + ftf.ttl.Milliseconds()
+	}
+
+	_ = ftf.store.StartFetching(userID, fetch)
 }
 
-func (ftf *freetextFetcher) MessageHasBeenPosted(c *plugin.Context, post *model.Post, api plugin.API, l logger.Logger, botUserID, pluginURL string) {
-	if botUserID == post.UserId {
-		return
+func (ftf *freetextFetcher) HandleReply(userID, message string, fetch Fetch, l logger.Logger, pluginURL string) {
+	if ftf.terminator != "" {
+		if !strings.EqualFold(strings.TrimSpace(message), ftf.terminator) {
+			fetch.Lines = append(fetch.Lines, message)
+			err := ftf.store.UpdateFetch(userID, fetch)
+			if err != nil {
+				l.Errorf("error storing the text line: %s", err.Error())
+			}
+			return
+		}
+		message = strings.Join(fetch.Lines, "\n")
 	}
 
-	ch, appErr := api.GetDirectChannel(botUserID, post.UserId)
-	if appErr != nil {
-		l.Errorf("error getting direct channel: %s", appErr.Error())
-		return
+	if ftf.parse != nil {
+		value, validation := ftf.parse(message)
+		if validation != "" {
+			ftf.failAttempt(userID, &fetch, validation, l)
+			return
+		}
+		message = value
 	}
 
-	if ch.Id != post.ChannelId {
-		return
+	if ftf.validate != nil {
+		validation := ftf.validate(message)
+		if validation != "" {
+			ftf.failAttempt(userID, &fetch, validation, l)
+			return
+		}
 	}
 
-	shouldProcess, payload, err := ftf.store.ShouldProcessFreetext(post.UserId, ftf.id)
+	ftf.postConfirmation(userID, message, pluginURL, fetch.Payload)
+	err := ftf.store.StopFetching(userID, fetch.ID)
 	if err != nil {
-		l.Errorf("error checking if should process the text: %s", err.Error())
+		l.Errorf("error stopping the text fetching: %s", err.Error())
 		return
 	}
+}
 
-	if !shouldProcess {
-		return
-	}
+func (ftf *freetextFetcher) HandleExpiry(userID string, fetch Fetch) {
+	ftf.endFetch(userID, fetch.Payload, ftf.expiredMessage)
+}
 
-	validation := ftf.validate(post.Message)
-	if validation != "" {
-		_, _ = ftf.poster.DM(post.UserId, validation)
+// failAttempt tells the user why the text was rejected, and gives up once the maximum number of
+// attempts is reached.
+func (ftf *freetextFetcher) failAttempt(userID string, fetch *Fetch, validation string, l logger.Logger) {
+	fetch.Attempts++
+	fetch.Lines = nil
+
+	if ftf.maxAttempts > 0 && fetch.Attempts >= ftf.maxAttempts {
+		err := ftf.store.StopFetching(userID, fetch.ID)
+		if err != nil {
+			l.Errorf("error stopping the text fetching: %s", err.Error())
+		}
+		_, _ = ftf.poster.DM(userID, validation)
+		ftf.endFetch(userID, fetch.Payload, ftf.giveUpMessage)
 		return
 	}
 
-	ftf.postConfirmation(post.UserId, post.Message, pluginURL, payload)
-	err = ftf.store.StopFetching(post.UserId)
+	err := ftf.store.UpdateFetch(userID, *fetch)
 	if err != nil {
-		l.Errorf("error stopping the text fetching: %s", err.Error())
-		return
+		l.Errorf("error storing the text fetching attempts: %s", err.Error())
 	}
+	_, _ = ftf.poster.DM(userID, validation)
+}
+
+func (ftf *freetextFetcher) endFetch(userID, payload, message string) {
+	if message != "" {
+		_, _ = ftf.poster.DM(userID, message)
+	}
+
+	if ftf.onCancel != nil {
+		ftf.onCancel(payload)
+	}
+}
+
+func (ftf *freetextFetcher) ID() string {
+	return ftf.id
 }
 
 func (ftf *freetextFetcher) URL() string {
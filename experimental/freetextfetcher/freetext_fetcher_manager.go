@@ -1,6 +1,10 @@
 package freetextfetcher
 
 import (
+	"sync"
+
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
 
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -10,29 +14,134 @@ import (
 // Manager defines the behavior of the freetext manager
 type Manager interface {
 	MessageHasBeenPosted(c *plugin.Context, post *model.Post, api plugin.API, l logger.Logger, botUserID string, pluginURL string)
+	// ExpireFetches drops the expired fetches of every user, notifying their fetchers. Expired
+	// fetches are otherwise only dropped when the user writes to the bot, so plugins using
+	// freetextfetcher.WithTTL should call it periodically, e.g. from a cluster job.
+	ExpireFetches() error
+	Add(ftf FreetextFetcher) error
+	Get(fetcherID string) FreetextFetcher
+	Store() FreetextStore
 	Clear()
 }
 
 type manager struct {
-	ftfList []FreetextFetcher
+	store    FreetextStore
+	lock     sync.RWMutex
+	fetchers map[string]FreetextFetcher
 }
 
-var ftfManager manager
+// NewManager creates a manager owning the fetchers created with it. Every fetcher keeps its
+// pending fetches in the given store, and the manager routes each message of a user to the
+// fetcher of the first pending fetch.
+func NewManager(store FreetextStore) Manager {
+	return &manager{
+		store:    store,
+		fetchers: map[string]FreetextFetcher{},
+	}
+}
 
-// GetManager gets the current manager or creates one if none is created
-func GetManager() Manager {
-	if ftfManager.ftfList == nil {
-		ftfManager.ftfList = []FreetextFetcher{}
+// Add registers a fetcher. Adding a fetcher with the ID of another one fails, since the pending
+// fetches of both could not be told apart.
+func (m *manager) Add(ftf FreetextFetcher) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.fetchers[ftf.ID()]; ok {
+		return errors.Errorf("a fetcher with id %s already exists", ftf.ID())
 	}
-	return &ftfManager
+
+	m.fetchers[ftf.ID()] = ftf
+	return nil
+}
+
+func (m *manager) Get(fetcherID string) FreetextFetcher {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.fetchers[fetcherID]
+}
+
+func (m *manager) Store() FreetextStore {
+	return m.store
 }
 
 func (m *manager) Clear() {
-	m.ftfList = []FreetextFetcher{}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.fetchers = map[string]FreetextFetcher{}
 }
 
 func (m *manager) MessageHasBeenPosted(c *plugin.Context, post *model.Post, api plugin.API, l logger.Logger, botUserID, pluginURL string) {
-	for _, v := range m.ftfList {
-		v.MessageHasBeenPosted(c, post, api, l, botUserID, pluginURL)
+	if botUserID == post.UserId {
+		return
+	}
+
+	ch, appErr := api.GetDirectChannel(botUserID, post.UserId)
+	if appErr != nil {
+		l.Errorf("error getting direct channel: %s", appErr.Error())
+		return
+	}
+
+	if ch.Id != post.ChannelId {
+		return
+	}
+
+	fetches, err := m.store.GetFetches(post.UserId)
+	if err != nil {
+		l.Errorf("error checking if should process the text: %s", err.Error())
+		return
+	}
+
+	now := model.GetMillis()
+	for _, fetch := range fetches {
+		ftf := m.Get(fetch.FetcherID)
+		if ftf != nil && !fetch.IsExpired(now) {
+			ftf.HandleReply(post.UserId, post.Message, fetch, l, pluginURL)
+			return
+		}
+
+		// Fetches of unknown fetchers, e.g. whose base URL changed or which are no longer created by
+		// the plugin, can never be answered, so they are dropped as well as the expired ones.
+		err = m.store.StopFetching(post.UserId, fetch.ID)
+		if err != nil {
+			l.Errorf("error stopping the text fetching: %s", err.Error())
+			return
+		}
+
+		if ftf != nil {
+			ftf.HandleExpiry(post.UserId, fetch)
+		}
+	}
+}
+
+func (m *manager) ExpireFetches() error {
+	userIDs, err := m.store.ListUserIDs()
+	if err != nil {
+		return errors.Wrap(err, "cannot list the users with pending fetches")
 	}
+
+	now := model.GetMillis()
+	for _, userID := range userIDs {
+		var fetches []Fetch
+		fetches, err = m.store.GetFetches(userID)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get the fetches of user %s", userID)
+		}
+
+		for _, fetch := range fetches {
+			if !fetch.IsExpired(now) {
+				continue
+			}
+
+			err = m.store.StopFetching(userID, fetch.ID)
+			if err != nil {
+				return errors.Wrapf(err, "cannot stop the fetch of user %s", userID)
+			}
+
+			ftf := m.Get(fetch.FetcherID)
+			if ftf != nil {
+				ftf.HandleExpiry(userID, fetch)
+			}
+		}
+	}
+
+	return nil
 }
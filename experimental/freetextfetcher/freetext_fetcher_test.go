@@ -0,0 +1,288 @@
+package freetextfetcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
+)
+
+type fakePoster struct {
+	poster.Poster
+	messages    []string
+	attachments []*model.SlackAttachment
+}
+
+func (p *fakePoster) DM(userID, format string, args ...interface{}) (string, error) {
+	p.messages = append(p.messages, format)
+	return model.NewId(), nil
+}
+
+func (p *fakePoster) DMWithAttachments(userID string, attachments ...*model.SlackAttachment) (string, error) {
+	p.attachments = append(p.attachments, attachments...)
+	return model.NewId(), nil
+}
+
+type memoryStore map[string][]Fetch
+
+func (s memoryStore) StartFetching(userID string, fetch Fetch) error {
+	s[userID] = append(s[userID], fetch)
+	return nil
+}
+
+func (s memoryStore) StopFetching(userID, fetchID string) error {
+	fetches := []Fetch{}
+	for _, f := range s[userID] {
+		if f.ID != fetchID {
+			fetches = append(fetches, f)
+		}
+	}
+	s[userID] = fetches
+	return nil
+}
+
+func (s memoryStore) UpdateFetch(userID string, fetch Fetch) error {
+	for i, f := range s[userID] {
+		if f.ID == fetch.ID {
+			s[userID][i] = fetch
+		}
+	}
+	return nil
+}
+
+func (s memoryStore) GetFetches(userID string) ([]Fetch, error) {
+	return s[userID], nil
+}
+
+func (s memoryStore) ListUserIDs() ([]string, error) {
+	userIDs := []string{}
+	for userID, fetches := range s {
+		if len(fetches) > 0 {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
+
+const (
+	botUserID = "bot-user-id"
+	userID    = "user-id"
+	channelID = "dm-channel-id"
+)
+
+type fetched struct {
+	messages []string
+	canceled []string
+}
+
+func newTestFetcher(t *testing.T, m Manager, p poster.Poster, validate func(string) string, options ...Option) (FreetextFetcher, *fetched) {
+	return newTestFetcherWithURL(t, "/freetext", m, p, validate, options...)
+}
+
+func newTestFetcherWithURL(t *testing.T, baseURL string, m Manager, p poster.Poster, validate func(string) string, options ...Option) (FreetextFetcher, *fetched) {
+	f := &fetched{}
+	ftf, err := NewFreetextFetcher(
+		baseURL,
+		m,
+		validate,
+		func(message, payload string) { f.messages = append(f.messages, message) },
+		func(payload string) { f.canceled = append(f.canceled, payload) },
+		mux.NewRouter(),
+		p,
+		options...,
+	)
+	require.NoError(t, err)
+	return ftf, f
+}
+
+func post(m Manager, message string) {
+	api := &plugintest.API{}
+	api.On("GetDirectChannel", botUserID, userID).Return(&model.Channel{Id: channelID}, nil)
+	m.MessageHasBeenPosted(nil, &model.Post{UserId: userID, ChannelId: channelID, Message: message}, api, logger.NewNilLogger(), botUserID, "/plugins/test")
+}
+
+func confirmed(p *fakePoster) []interface{} {
+	messages := []interface{}{}
+	for _, sa := range p.attachments {
+		messages = append(messages, sa.Actions[0].Integration.Context[ContextMessageKey])
+	}
+	return messages
+}
+
+func TestFreetextFetcher(t *testing.T) {
+	t.Run("queue", func(t *testing.T) {
+		store := memoryStore{}
+		m := NewManager(store)
+		p := &fakePoster{}
+		first, _ := newTestFetcherWithURL(t, "/first", m, p, nil)
+		second, _ := newTestFetcherWithURL(t, "/second", m, p, nil)
+
+		first.StartFetching(userID, "first")
+		second.StartFetching(userID, "second")
+		require.Len(t, store[userID], 2)
+
+		post(m, "one")
+		post(m, "two")
+		post(m, "ignored")
+
+		assert.Equal(t, []interface{}{"one", "two"}, confirmed(p))
+		assert.Empty(t, store[userID])
+	})
+
+	t.Run("managers are independent", func(t *testing.T) {
+		store := memoryStore{}
+		m := NewManager(store)
+		other := NewManager(store)
+		p := &fakePoster{}
+		ftf, _ := newTestFetcher(t, m, p, nil)
+
+		assert.Equal(t, ftf, m.Get(ftf.ID()))
+		assert.Nil(t, other.Get(ftf.ID()))
+
+		m.Clear()
+		assert.Nil(t, m.Get(ftf.ID()))
+	})
+
+	t.Run("stable id", func(t *testing.T) {
+		store := memoryStore{}
+		p := &fakePoster{}
+		ftf, _ := newTestFetcher(t, NewManager(store), p, nil)
+		ftf.StartFetching(userID, "payload")
+
+		// A new activation of the plugin creates the fetcher again, and answers its pending fetch.
+		m := NewManager(store)
+		restarted, _ := newTestFetcher(t, m, p, nil)
+		assert.Equal(t, ftf.ID(), restarted.ID())
+		assert.NotEqual(t, ftf.ID(), fetcherID("/other"))
+
+		post(m, "hello")
+		assert.Equal(t, []interface{}{"hello"}, confirmed(p))
+		assert.Empty(t, store[userID])
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		m := NewManager(memoryStore{})
+		ftf, _ := newTestFetcher(t, m, &fakePoster{}, nil)
+
+		assert.Error(t, m.Add(ftf))
+		_, err := NewFreetextFetcher("/freetext", m, nil, nil, nil, mux.NewRouter(), &fakePoster{})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown fetcher", func(t *testing.T) {
+		store := memoryStore{}
+		m := NewManager(store)
+		p := &fakePoster{}
+		ftf, _ := newTestFetcher(t, m, p, nil)
+
+		store[userID] = []Fetch{{ID: model.NewId(), FetcherID: "removed"}}
+		ftf.StartFetching(userID, "payload")
+
+		post(m, "hello")
+		assert.Equal(t, []interface{}{"hello"}, confirmed(p))
+		assert.Empty(t, store[userID])
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		store := memoryStore{}
+		m := NewManager(store)
+		p := &fakePoster{}
+		ftf, f := newTestFetcher(t, m, p, nil, WithTTL(time.Minute, "Too late."))
+
+		ftf.StartFetching(userID, "payload")
+		fetch := store[userID][0]
+		fetch.ExpiresAt = model.GetMillis() - 1
+		store[userID][0] = fetch
+
+		post(m, "hello")
+		assert.Empty(t, p.attachments)
+		assert.Equal(t, []string{"Too late."}, p.messages)
+		assert.Equal(t, []string{"payload"}, f.canceled)
+		assert.Empty(t, store[userID])
+	})
+
+	t.Run("expire fetches", func(t *testing.T) {
+		store := memoryStore{}
+		m := NewManager(store)
+		p := &fakePoster{}
+		ftf, f := newTestFetcher(t, m, p, nil, WithTTL(time.Minute, "Too late."))
+
+		ftf.StartFetching(userID, "expired")
+		ftf.StartFetching(userID, "pending")
+		fetch := store[userID][0]
+		fetch.ExpiresAt = model.GetMillis() - 1
+		store[userID][0] = fetch
+
+		require.NoError(t, m.ExpireFetches())
+		assert.Equal(t, []string{"Too late."}, p.messages)
+		assert.Equal(t, []string{"expired"}, f.canceled)
+		require.Len(t, store[userID], 1)
+		assert.Equal(t, "pending", store[userID][0].Payload)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		store := memoryStore{}
+		m := NewManager(store)
+		p := &fakePoster{}
+		ftf, f := newTestFetcher(t, m, p, nil, WithParser(ParseNumber), WithMaxAttempts(2, "Giving up."))
+
+		ftf.StartFetching(userID, "payload")
+		post(m, "one")
+		require.Len(t, store[userID], 1)
+		assert.Equal(t, 1, store[userID][0].Attempts)
+
+		post(m, "two")
+		assert.Equal(t, []string{"Please write a number.", "Please write a number.", "Giving up."}, p.messages)
+		assert.Equal(t, []string{"payload"}, f.canceled)
+		assert.Empty(t, store[userID])
+	})
+
+	t.Run("multiline", func(t *testing.T) {
+		store := memoryStore{}
+		m := NewManager(store)
+		p := &fakePoster{}
+		ftf, _ := newTestFetcher(t, m, p, func(message string) string {
+			if message == "" {
+				return "Write something first."
+			}
+			return ""
+		}, WithMultiline("done"))
+
+		ftf.StartFetching(userID, "payload")
+		post(m, "Done")
+		post(m, "first line")
+		post(m, "second line")
+		post(m, " DONE ")
+
+		assert.Equal(t, []string{"Write something first."}, p.messages)
+		assert.Equal(t, []interface{}{"first line\nsecond line"}, confirmed(p))
+	})
+}
+
+func TestParsers(t *testing.T) {
+	for _, tc := range []struct {
+		parse      Parser
+		message    string
+		value      string
+		validation bool
+	}{
+		{ParseNumber, " 42.50 ", "42.5", false},
+		{ParseNumber, "forty", "", true},
+		{ParseDate, "2021-03-04", "2021-03-04", false},
+		{ParseDate, "Mar 4, 2021", "2021-03-04", false},
+		{ParseDate, "yesterday", "", true},
+		{ParseEmail, "Jane Doe <Jane@Example.com>", "Jane@example.com", false},
+		{ParseEmail, "jane", "", true},
+	} {
+		value, validation := tc.parse(tc.message)
+		assert.Equal(t, tc.value, value, tc.message)
+		assert.Equal(t, tc.validation, validation != "", tc.message)
+	}
+}
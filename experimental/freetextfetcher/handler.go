@@ -61,7 +61,7 @@ func (ftf *freetextFetcher) handle(w http.ResponseWriter, r *http.Request) {
 
 	action, ok := request.Context[ContextActionKey].(string)
 	if !ok {
-		_ = ftf.store.StartFetching(mattermostUserID, ftf.id, payload)
+		ftf.StartFetching(mattermostUserID, payload)
 		writeConfirmResponse(w, "Write your input.")
 		return
 	}
@@ -7,8 +7,7 @@ package mock_freetext_fetcher
 import (
 	gomock "github.com/golang/mock/gomock"
 	logger "github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
-	model "github.com/mattermost/mattermost-server/v5/model"
-	plugin "github.com/mattermost/mattermost-server/v5/plugin"
+	freetextfetcher "github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
 	reflect "reflect"
 )
 
@@ -35,16 +34,42 @@ func (m *MockFreetextFetcher) EXPECT() *MockFreetextFetcherMockRecorder {
 	return m.recorder
 }
 
-// MessageHasBeenPosted mocks base method
-func (m *MockFreetextFetcher) MessageHasBeenPosted(arg0 *plugin.Context, arg1 *model.Post, arg2 plugin.API, arg3 logger.Logger, arg4, arg5 string) {
+// HandleExpiry mocks base method
+func (m *MockFreetextFetcher) HandleExpiry(arg0 string, arg1 freetextfetcher.Fetch) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "MessageHasBeenPosted", arg0, arg1, arg2, arg3, arg4, arg5)
+	m.ctrl.Call(m, "HandleExpiry", arg0, arg1)
 }
 
-// MessageHasBeenPosted indicates an expected call of MessageHasBeenPosted
-func (mr *MockFreetextFetcherMockRecorder) MessageHasBeenPosted(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+// HandleExpiry indicates an expected call of HandleExpiry
+func (mr *MockFreetextFetcherMockRecorder) HandleExpiry(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageHasBeenPosted", reflect.TypeOf((*MockFreetextFetcher)(nil).MessageHasBeenPosted), arg0, arg1, arg2, arg3, arg4, arg5)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleExpiry", reflect.TypeOf((*MockFreetextFetcher)(nil).HandleExpiry), arg0, arg1)
+}
+
+// HandleReply mocks base method
+func (m *MockFreetextFetcher) HandleReply(arg0, arg1 string, arg2 freetextfetcher.Fetch, arg3 logger.Logger, arg4 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleReply", arg0, arg1, arg2, arg3, arg4)
+}
+
+// HandleReply indicates an expected call of HandleReply
+func (mr *MockFreetextFetcherMockRecorder) HandleReply(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleReply", reflect.TypeOf((*MockFreetextFetcher)(nil).HandleReply), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ID mocks base method
+func (m *MockFreetextFetcher) ID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ID indicates an expected call of ID
+func (mr *MockFreetextFetcherMockRecorder) ID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ID", reflect.TypeOf((*MockFreetextFetcher)(nil).ID))
 }
 
 // StartFetching mocks base method
@@ -7,6 +7,7 @@ package mock_freetext_fetcher
 import (
 	gomock "github.com/golang/mock/gomock"
 	logger "github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	freetextfetcher "github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
 	model "github.com/mattermost/mattermost-server/v5/model"
 	plugin "github.com/mattermost/mattermost-server/v5/plugin"
 	reflect "reflect"
@@ -35,6 +36,20 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// Add mocks base method
+func (m *MockManager) Add(arg0 freetextfetcher.FreetextFetcher) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add
+func (mr *MockManagerMockRecorder) Add(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockManager)(nil).Add), arg0)
+}
+
 // Clear mocks base method
 func (m *MockManager) Clear() {
 	m.ctrl.T.Helper()
@@ -47,6 +62,34 @@ func (mr *MockManagerMockRecorder) Clear() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockManager)(nil).Clear))
 }
 
+// ExpireFetches mocks base method
+func (m *MockManager) ExpireFetches() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireFetches")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireFetches indicates an expected call of ExpireFetches
+func (mr *MockManagerMockRecorder) ExpireFetches() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireFetches", reflect.TypeOf((*MockManager)(nil).ExpireFetches))
+}
+
+// Get mocks base method
+func (m *MockManager) Get(arg0 string) freetextfetcher.FreetextFetcher {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(freetextfetcher.FreetextFetcher)
+	return ret0
+}
+
+// Get indicates an expected call of Get
+func (mr *MockManagerMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockManager)(nil).Get), arg0)
+}
+
 // MessageHasBeenPosted mocks base method
 func (m *MockManager) MessageHasBeenPosted(arg0 *plugin.Context, arg1 *model.Post, arg2 plugin.API, arg3 logger.Logger, arg4, arg5 string) {
 	m.ctrl.T.Helper()
@@ -58,3 +101,17 @@ func (mr *MockManagerMockRecorder) MessageHasBeenPosted(arg0, arg1, arg2, arg3,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageHasBeenPosted", reflect.TypeOf((*MockManager)(nil).MessageHasBeenPosted), arg0, arg1, arg2, arg3, arg4, arg5)
 }
+
+// Store mocks base method
+func (m *MockManager) Store() freetextfetcher.FreetextStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store")
+	ret0, _ := ret[0].(freetextfetcher.FreetextStore)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockManagerMockRecorder) Store() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockManager)(nil).Store))
+}
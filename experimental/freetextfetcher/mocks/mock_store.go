@@ -6,6 +6,7 @@ package mock_freetext_fetcher
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	freetextfetcher "github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
 	reflect "reflect"
 )
 
@@ -32,46 +33,74 @@ func (m *MockFreetextStore) EXPECT() *MockFreetextStoreMockRecorder {
 	return m.recorder
 }
 
-// ShouldProcessFreetext mocks base method
-func (m *MockFreetextStore) ShouldProcessFreetext(arg0, arg1 string) (bool, string, error) {
+// GetFetches mocks base method
+func (m *MockFreetextStore) GetFetches(arg0 string) ([]freetextfetcher.Fetch, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ShouldProcessFreetext", arg0, arg1)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(string)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "GetFetches", arg0)
+	ret0, _ := ret[0].([]freetextfetcher.Fetch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// ShouldProcessFreetext indicates an expected call of ShouldProcessFreetext
-func (mr *MockFreetextStoreMockRecorder) ShouldProcessFreetext(arg0, arg1 interface{}) *gomock.Call {
+// GetFetches indicates an expected call of GetFetches
+func (mr *MockFreetextStoreMockRecorder) GetFetches(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldProcessFreetext", reflect.TypeOf((*MockFreetextStore)(nil).ShouldProcessFreetext), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFetches", reflect.TypeOf((*MockFreetextStore)(nil).GetFetches), arg0)
+}
+
+// ListUserIDs mocks base method
+func (m *MockFreetextStore) ListUserIDs() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserIDs")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserIDs indicates an expected call of ListUserIDs
+func (mr *MockFreetextStoreMockRecorder) ListUserIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserIDs", reflect.TypeOf((*MockFreetextStore)(nil).ListUserIDs))
 }
 
 // StartFetching mocks base method
-func (m *MockFreetextStore) StartFetching(arg0, arg1, arg2 string) error {
+func (m *MockFreetextStore) StartFetching(arg0 string, arg1 freetextfetcher.Fetch) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StartFetching", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "StartFetching", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StartFetching indicates an expected call of StartFetching
-func (mr *MockFreetextStoreMockRecorder) StartFetching(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockFreetextStoreMockRecorder) StartFetching(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartFetching", reflect.TypeOf((*MockFreetextStore)(nil).StartFetching), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartFetching", reflect.TypeOf((*MockFreetextStore)(nil).StartFetching), arg0, arg1)
 }
 
 // StopFetching mocks base method
-func (m *MockFreetextStore) StopFetching(arg0 string) error {
+func (m *MockFreetextStore) StopFetching(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StopFetching", arg0)
+	ret := m.ctrl.Call(m, "StopFetching", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StopFetching indicates an expected call of StopFetching
-func (mr *MockFreetextStoreMockRecorder) StopFetching(arg0 interface{}) *gomock.Call {
+func (mr *MockFreetextStoreMockRecorder) StopFetching(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopFetching", reflect.TypeOf((*MockFreetextStore)(nil).StopFetching), arg0, arg1)
+}
+
+// UpdateFetch mocks base method
+func (m *MockFreetextStore) UpdateFetch(arg0 string, arg1 freetextfetcher.Fetch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFetch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFetch indicates an expected call of UpdateFetch
+func (mr *MockFreetextStoreMockRecorder) UpdateFetch(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopFetching", reflect.TypeOf((*MockFreetextStore)(nil).StopFetching), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFetch", reflect.TypeOf((*MockFreetextStore)(nil).UpdateFetch), arg0, arg1)
 }
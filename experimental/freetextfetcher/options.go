@@ -0,0 +1,97 @@
+package freetextfetcher
+
+import (
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Option defines each option that can be passed in the creation of the FreetextFetcher.
+type Option func(*freetextFetcher)
+
+// Parser converts the text written by the user into its canonical form before validation. Like
+// validate, it returns a non empty message when the text is not valid, which is sent back to the
+// user and counts as a failed attempt.
+type Parser func(message string) (value, validation string)
+
+// WithTTL makes fetches expire after the given duration. Replies to an expired fetch are
+// ignored, the message is sent to the user if not empty, and onCancel is called. Expired fetches
+// are noticed when the user next writes to the bot, or when Manager.ExpireFetches runs.
+func WithTTL(ttl time.Duration, expiredMessage string) Option {
+	return func(ftf *freetextFetcher) {
+		ftf.ttl = ttl
+		ftf.expiredMessage = expiredMessage
+	}
+}
+
+// WithMaxAttempts stops the fetch after the given number of replies failing parsing or
+// validation. The message is sent to the user if not empty, and onCancel is called.
+func WithMaxAttempts(maxAttempts int, giveUpMessage string) Option {
+	return func(ftf *freetextFetcher) {
+		ftf.maxAttempts = maxAttempts
+		ftf.giveUpMessage = giveUpMessage
+	}
+}
+
+// WithMultiline captures every message of the user, as separate lines, until a message only
+// containing the terminator is written.
+func WithMultiline(terminator string) Option {
+	return func(ftf *freetextFetcher) {
+		ftf.terminator = terminator
+	}
+}
+
+// WithParser parses the text written by the user before it is validated and passed to onFetch.
+func WithParser(parse Parser) Option {
+	return func(ftf *freetextFetcher) {
+		ftf.parse = parse
+	}
+}
+
+// ParseNumber accepts any decimal number.
+func ParseNumber(message string) (value, validation string) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(message), 64)
+	if err != nil {
+		return "", "Please write a number."
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64), ""
+}
+
+// dateLayouts are the layouts accepted by ParseDate.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"Jan 2, 2006",
+	"Jan 2 2006",
+	"January 2, 2006",
+	"January 2 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// ParseDate accepts dates like 2006-01-02 or Jan 2, 2006, and formats them as 2006-01-02.
+func ParseDate(message string) (value, validation string) {
+	message = strings.TrimSpace(message)
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, message)
+		if err == nil {
+			return t.Format("2006-01-02"), ""
+		}
+	}
+
+	return "", "Please write a date, e.g. 2006-01-02."
+}
+
+// ParseEmail accepts an email address, with or without a display name, and returns the address.
+func ParseEmail(message string) (value, validation string) {
+	address, err := mail.ParseAddress(strings.TrimSpace(message))
+	if err != nil {
+		return "", "Please write a valid email address."
+	}
+
+	// Only the domain is case insensitive.
+	at := strings.LastIndex(address.Address, "@")
+	return address.Address[:at] + strings.ToLower(address.Address[at:]), ""
+}
@@ -1,16 +1,49 @@
 package freetextfetcher
 
 import (
-	"errors"
+	"encoding/json"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 )
 
+const listKeysPerPage = 1000
+
+// Fetch is a pending free text fetch of a user. Only the first fetch of the queue of a user is
+// active; the rest wait until it is completed, canceled or expired.
+type Fetch struct {
+	ID        string
+	FetcherID string
+	Payload   string
+	// ExpiresAt is the time, in milliseconds, after which the fetch is dropped. Zero means the
+	// fetch never expires.
+	ExpiresAt int64
+	// Attempts counts the replies that failed parsing or validation.
+	Attempts int
+	// Lines holds the lines captured so far by multi-line fetches.
+	Lines []string
+}
+
+// IsExpired returns true if the fetch has expired at the given time, in milliseconds.
+func (f *Fetch) IsExpired(now int64) bool {
+	return f.ExpiresAt != 0 && now >= f.ExpiresAt
+}
+
 // FreetextStore defines the behavior needed to store all the data for the FreetextFetcher
 type FreetextStore interface {
-	StartFetching(userID, fetcherID, payload string) error
-	StopFetching(userID string) error
-	ShouldProcessFreetext(userID, fetcherID string) (bool, string, error)
+	// StartFetching adds the fetch at the end of the queue of the user, replacing any fetch of the
+	// same fetcher with the same payload.
+	StartFetching(userID string, fetch Fetch) error
+	// StopFetching removes the fetch with the given ID from the queue of the user.
+	StopFetching(userID, fetchID string) error
+	// UpdateFetch replaces the fetch with the same ID in the queue of the user.
+	UpdateFetch(userID string, fetch Fetch) error
+	// GetFetches returns the queue of fetches of the user.
+	GetFetches(userID string) ([]Fetch, error)
+	// ListUserIDs returns the IDs of the users with pending fetches.
+	ListUserIDs() ([]string, error)
 }
 
 type freetextStore struct {
@@ -18,11 +51,6 @@ type freetextStore struct {
 	keyPrefix string
 }
 
-type storeElement struct {
-	fetcherID string
-	payload   string
-}
-
 // NewFreetextStore creates a new store for the FreetextFetcher
 func NewFreetextStore(apiClient pluginapi.Client, keyPrefix string) FreetextStore {
 	return &freetextStore{
@@ -31,37 +59,92 @@ func NewFreetextStore(apiClient pluginapi.Client, keyPrefix string) FreetextStor
 	}
 }
 
-func (fts *freetextStore) StartFetching(userID, fetcherID, payload string) error {
-	toStore := storeElement{
-		fetcherID: fetcherID,
-		payload:   payload,
-	}
-	ok, err := fts.client.KV.Set(fts.getKey(userID), toStore)
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return errors.New("value not set without errors")
-	}
-	return nil
+func (fts *freetextStore) StartFetching(userID string, fetch Fetch) error {
+	return fts.updateFetches(userID, func(fetches []Fetch) []Fetch {
+		updated := []Fetch{}
+		for _, f := range fetches {
+			if f.FetcherID != fetch.FetcherID || f.Payload != fetch.Payload {
+				updated = append(updated, f)
+			}
+		}
+		return append(updated, fetch)
+	})
+}
+
+func (fts *freetextStore) StopFetching(userID, fetchID string) error {
+	return fts.updateFetches(userID, func(fetches []Fetch) []Fetch {
+		updated := []Fetch{}
+		for _, f := range fetches {
+			if f.ID != fetchID {
+				updated = append(updated, f)
+			}
+		}
+		return updated
+	})
 }
 
-func (fts *freetextStore) StopFetching(userID string) error {
-	return fts.client.KV.Delete(fts.getKey(userID))
+func (fts *freetextStore) UpdateFetch(userID string, fetch Fetch) error {
+	return fts.updateFetches(userID, func(fetches []Fetch) []Fetch {
+		for i, f := range fetches {
+			if f.ID == fetch.ID {
+				fetches[i] = fetch
+			}
+		}
+		return fetches
+	})
 }
 
-func (fts *freetextStore) ShouldProcessFreetext(userID, fetcherID string) (shouldProcess bool, payload string, err error) {
-	var se storeElement
-	err = fts.client.KV.Get(fts.getKey(userID), &se)
+func (fts *freetextStore) GetFetches(userID string) ([]Fetch, error) {
+	var fetches []Fetch
+	err := fts.client.KV.Get(fts.getKey(userID), &fetches)
 	if err != nil {
-		return false, "", err
+		return nil, err
 	}
 
-	if se.fetcherID != fetcherID {
-		return false, "", nil
+	return fetches, nil
+}
+
+func (fts *freetextStore) ListUserIDs() ([]string, error) {
+	prefix := fts.getKey("")
+
+	userIDs := []string{}
+	for page := 0; ; page++ {
+		listed := 0
+		keys, err := fts.client.KV.ListKeys(page, listKeysPerPage, pluginapi.WithChecker(func(key string) (bool, error) {
+			listed++
+			return strings.HasPrefix(key, prefix) && model.IsValidId(strings.TrimPrefix(key, prefix)), nil
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			userIDs = append(userIDs, strings.TrimPrefix(key, prefix))
+		}
+
+		if listed < listKeysPerPage {
+			return userIDs, nil
+		}
 	}
+}
+
+func (fts *freetextStore) updateFetches(userID string, update func([]Fetch) []Fetch) error {
+	return fts.client.KV.SetAtomicWithRetries(fts.getKey(userID), func(oldValue []byte) (interface{}, error) {
+		var fetches []Fetch
+		if len(oldValue) > 0 {
+			err := json.Unmarshal(oldValue, &fetches)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		fetches = update(fetches)
+		if len(fetches) == 0 {
+			return nil, nil
+		}
 
-	return true, se.payload, nil
+		return fetches, nil
+	})
 }
 
 func (fts *freetextStore) getKey(userID string) string {
@@ -0,0 +1,206 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package oauther
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+// EventType is the type of a connection lifecycle event.
+type EventType string
+
+const (
+	// EventConnected is sent when a user completes the OAuth flow.
+	EventConnected EventType = "connected"
+	// EventRefreshed is sent when the token of a user is refreshed.
+	EventRefreshed EventType = "refreshed"
+	// EventRefreshFailed is sent when the token of a user cannot be refreshed.
+	EventRefreshFailed EventType = "refresh_failed"
+	// EventDisconnected is sent when the token of a user is removed.
+	EventDisconnected EventType = "disconnected"
+)
+
+// Event is a connection lifecycle event, sent to the listener set with EventListener.
+type Event struct {
+	Type     EventType
+	UserID   string
+	Provider string
+	// Err is the error of a failed refresh, or of a failed revocation when disconnecting.
+	Err error
+}
+
+// Connection describes the token of a connected user.
+type Connection struct {
+	UserID   string
+	Provider string
+	Expiry   time.Time
+	Scopes   []string
+}
+
+const (
+	listKeysPerPage = 1000
+	revokeTimeout   = 10 * time.Second
+)
+
+func (o *oAuther) sendEvent(eventType EventType, userID string, err error) {
+	if o.eventListener == nil {
+		return
+	}
+
+	o.eventListener(Event{
+		Type:     eventType,
+		UserID:   userID,
+		Provider: o.providerID,
+		Err:      err,
+	})
+}
+
+func (o *oAuther) Deauthorize(userID string) error {
+	var revokeErr error
+	if o.revokeURL != "" {
+		token, err := o.GetToken(userID)
+		if err != nil {
+			return err
+		}
+		if token != nil {
+			revokeErr = o.revoke(token)
+			if revokeErr != nil {
+				o.logger.Warnf("cannot revoke the token of user %s, err=%s", userID, revokeErr.Error())
+			}
+		}
+	}
+
+	err := o.store.Delete(o.getTokenKey(userID))
+	if err != nil {
+		return err
+	}
+
+	err = o.store.Delete(o.getTokenInfoKey(userID))
+	if err != nil {
+		return err
+	}
+
+	o.sendEvent(EventDisconnected, userID, revokeErr)
+	return nil
+}
+
+// revoke asks the provider to revoke the token, following RFC 7009. Revoking the refresh token
+// also revokes the access tokens issued with it.
+func (o *oAuther) revoke(token *oauth2.Token) error {
+	data := url.Values{}
+	if token.RefreshToken != "" {
+		data.Set("token", token.RefreshToken)
+		data.Set("token_type_hint", "refresh_token")
+	} else {
+		data.Set("token", token.AccessToken)
+		data.Set("token_type_hint", "access_token")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), revokeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.revokeURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(o.config.ClientID), url.QueryEscape(o.config.ClientSecret))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("revoke endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (o *oAuther) ListConnections() ([]Connection, error) {
+	userIDs, err := o.listConnectedUserIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	connections := []Connection{}
+	for _, userID := range userIDs {
+		token, err := o.GetToken(userID)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			continue
+		}
+
+		connection := Connection{
+			UserID:   userID,
+			Provider: o.providerID,
+			Expiry:   token.Expiry,
+		}
+
+		info, err := o.GetTokenInfo(userID)
+		if err != nil {
+			return nil, err
+		}
+		if info != nil {
+			connection.Scopes = info.Scopes
+		}
+
+		connections = append(connections, connection)
+	}
+
+	return connections, nil
+}
+
+func (o *oAuther) DisconnectAll() (int, error) {
+	userIDs, err := o.listConnectedUserIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, userID := range userIDs {
+		err = o.Deauthorize(userID)
+		if err != nil {
+			return i, errors.Wrapf(err, "cannot disconnect user %s", userID)
+		}
+	}
+
+	return len(userIDs), nil
+}
+
+func (o *oAuther) listConnectedUserIDs() ([]string, error) {
+	prefix := o.getTokenKey("")
+
+	userIDs := []string{}
+	for page := 0; ; page++ {
+		listed := 0
+		keys, err := o.store.ListKeys(page, listKeysPerPage, pluginapi.WithChecker(func(key string) (bool, error) {
+			listed++
+			return strings.HasPrefix(key, prefix), nil
+		}))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			userIDs = append(userIDs, strings.TrimPrefix(key, prefix))
+		}
+
+		if listed < listKeysPerPage {
+			return userIDs, nil
+		}
+	}
+}
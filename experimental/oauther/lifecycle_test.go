@@ -0,0 +1,81 @@
+package oauther
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+)
+
+func TestLifecycle(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		user, _, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "client", user)
+		revoked = append(revoked, r.Form.Get("token"))
+	}))
+	defer server.Close()
+
+	events := []Event{}
+	client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+	r := NewRegistry("https://example.com/plugins/test", "", &client.KV, logger.NewNilLogger())
+	o, err := r.Register("jira", oauth2.Config{ClientID: "client"}, nil,
+		RevokeURL(server.URL),
+		EventListener(func(event Event) {
+			events = append(events, event)
+		}),
+	)
+	require.NoError(t, err)
+
+	prefix := providerStorePrefix("jira")
+	expiry := time.Now().Add(time.Hour).Round(time.Second).UTC()
+	users := []string{model.NewId(), model.NewId()}
+	for _, userID := range users {
+		_, err = client.KV.Set(prefix+"token_"+userID, &oauth2.Token{AccessToken: "access", RefreshToken: "refresh-" + userID, Expiry: expiry})
+		require.NoError(t, err)
+	}
+	_, err = client.KV.Set(prefix+"tokeninfo_"+users[0], TokenInfo{Provider: "jira", Scopes: []string{"read"}})
+	require.NoError(t, err)
+	_, err = client.KV.Set(prefix+"state_"+users[0], "not a token")
+	require.NoError(t, err)
+
+	connections, err := r.ListConnections()
+	require.NoError(t, err)
+	require.Len(t, connections, 2)
+	for _, connection := range connections {
+		assert.Equal(t, "jira", connection.Provider)
+		assert.True(t, expiry.Equal(connection.Expiry))
+		if connection.UserID == users[0] {
+			assert.Equal(t, []string{"read"}, connection.Scopes)
+		} else {
+			assert.Equal(t, users[1], connection.UserID)
+			assert.Empty(t, connection.Scopes)
+		}
+	}
+
+	require.NoError(t, o.Deauthorize(users[0]))
+	assert.Equal(t, []string{"refresh-" + users[0]}, revoked)
+	require.Len(t, events, 1)
+	assert.Equal(t, Event{Type: EventDisconnected, UserID: users[0], Provider: "jira"}, events[0])
+
+	disconnected, err := r.DisconnectAll()
+	require.NoError(t, err)
+	assert.Equal(t, 1, disconnected)
+	assert.Len(t, revoked, 2)
+	assert.Len(t, events, 2)
+
+	connections, err = o.ListConnections()
+	require.NoError(t, err)
+	assert.Empty(t, connections)
+}
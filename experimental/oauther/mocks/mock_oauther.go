@@ -5,7 +5,9 @@
 package mock_oauther
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
+	oauther "github.com/mattermost/mattermost-plugin-api/experimental/oauther"
 	oauth2 "golang.org/x/oauth2"
 	http "net/http"
 	reflect "reflect"
@@ -48,6 +50,21 @@ func (mr *MockOAutherMockRecorder) AddPayload(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPayload", reflect.TypeOf((*MockOAuther)(nil).AddPayload), arg0, arg1)
 }
 
+// Client mocks base method
+func (m *MockOAuther) Client(arg0 context.Context, arg1 string) (*http.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Client", arg0, arg1)
+	ret0, _ := ret[0].(*http.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Client indicates an expected call of Client
+func (mr *MockOAutherMockRecorder) Client(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Client", reflect.TypeOf((*MockOAuther)(nil).Client), arg0, arg1)
+}
+
 // Deauthorize mocks base method
 func (m *MockOAuther) Deauthorize(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -62,6 +79,21 @@ func (mr *MockOAutherMockRecorder) Deauthorize(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deauthorize", reflect.TypeOf((*MockOAuther)(nil).Deauthorize), arg0)
 }
 
+// DisconnectAll mocks base method
+func (m *MockOAuther) DisconnectAll() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisconnectAll")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisconnectAll indicates an expected call of DisconnectAll
+func (mr *MockOAutherMockRecorder) DisconnectAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisconnectAll", reflect.TypeOf((*MockOAuther)(nil).DisconnectAll))
+}
+
 // GetConnectURL mocks base method
 func (m *MockOAuther) GetConnectURL() string {
 	m.ctrl.T.Helper()
@@ -76,6 +108,24 @@ func (mr *MockOAutherMockRecorder) GetConnectURL() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConnectURL", reflect.TypeOf((*MockOAuther)(nil).GetConnectURL))
 }
 
+// GetConnectURLWithScopes mocks base method
+func (m *MockOAuther) GetConnectURLWithScopes(arg0 ...string) string {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetConnectURLWithScopes", varargs...)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetConnectURLWithScopes indicates an expected call of GetConnectURLWithScopes
+func (mr *MockOAutherMockRecorder) GetConnectURLWithScopes(arg0 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConnectURLWithScopes", reflect.TypeOf((*MockOAuther)(nil).GetConnectURLWithScopes), arg0...)
+}
+
 // GetToken mocks base method
 func (m *MockOAuther) GetToken(arg0 string) (*oauth2.Token, error) {
 	m.ctrl.T.Helper()
@@ -91,6 +141,50 @@ func (mr *MockOAutherMockRecorder) GetToken(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetToken", reflect.TypeOf((*MockOAuther)(nil).GetToken), arg0)
 }
 
+// GetTokenInfo mocks base method
+func (m *MockOAuther) GetTokenInfo(arg0 string) (*oauther.TokenInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenInfo", arg0)
+	ret0, _ := ret[0].(*oauther.TokenInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenInfo indicates an expected call of GetTokenInfo
+func (mr *MockOAutherMockRecorder) GetTokenInfo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenInfo", reflect.TypeOf((*MockOAuther)(nil).GetTokenInfo), arg0)
+}
+
+// ListConnections mocks base method
+func (m *MockOAuther) ListConnections() ([]oauther.Connection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConnections")
+	ret0, _ := ret[0].([]oauther.Connection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConnections indicates an expected call of ListConnections
+func (mr *MockOAutherMockRecorder) ListConnections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConnections", reflect.TypeOf((*MockOAuther)(nil).ListConnections))
+}
+
+// ProviderID mocks base method
+func (m *MockOAuther) ProviderID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProviderID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ProviderID indicates an expected call of ProviderID
+func (mr *MockOAutherMockRecorder) ProviderID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderID", reflect.TypeOf((*MockOAuther)(nil).ProviderID))
+}
+
 // ServeHTTP mocks base method
 func (m *MockOAuther) ServeHTTP(arg0 http.ResponseWriter, arg1 *http.Request) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-api/experimental/oauther (interfaces: Registry)
+
+// Package mock_oauther is a generated GoMock package.
+package mock_oauther
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	oauther "github.com/mattermost/mattermost-plugin-api/experimental/oauther"
+	oauth2 "golang.org/x/oauth2"
+	http "net/http"
+	reflect "reflect"
+)
+
+// MockRegistry is a mock of Registry interface
+type MockRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistryMockRecorder
+}
+
+// MockRegistryMockRecorder is the mock recorder for MockRegistry
+type MockRegistryMockRecorder struct {
+	mock *MockRegistry
+}
+
+// NewMockRegistry creates a new mock instance
+func NewMockRegistry(ctrl *gomock.Controller) *MockRegistry {
+	mock := &MockRegistry{ctrl: ctrl}
+	mock.recorder = &MockRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRegistry) EXPECT() *MockRegistryMockRecorder {
+	return m.recorder
+}
+
+// ConnectedProviders mocks base method
+func (m *MockRegistry) ConnectedProviders(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectedProviders", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConnectedProviders indicates an expected call of ConnectedProviders
+func (mr *MockRegistryMockRecorder) ConnectedProviders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectedProviders", reflect.TypeOf((*MockRegistry)(nil).ConnectedProviders), arg0)
+}
+
+// DisconnectAll mocks base method
+func (m *MockRegistry) DisconnectAll() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisconnectAll")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisconnectAll indicates an expected call of DisconnectAll
+func (mr *MockRegistryMockRecorder) DisconnectAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisconnectAll", reflect.TypeOf((*MockRegistry)(nil).DisconnectAll))
+}
+
+// Get mocks base method
+func (m *MockRegistry) Get(arg0 string) oauther.OAuther {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(oauther.OAuther)
+	return ret0
+}
+
+// Get indicates an expected call of Get
+func (mr *MockRegistryMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRegistry)(nil).Get), arg0)
+}
+
+// ListConnections mocks base method
+func (m *MockRegistry) ListConnections() ([]oauther.Connection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConnections")
+	ret0, _ := ret[0].([]oauther.Connection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConnections indicates an expected call of ListConnections
+func (mr *MockRegistryMockRecorder) ListConnections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConnections", reflect.TypeOf((*MockRegistry)(nil).ListConnections))
+}
+
+// Providers mocks base method
+func (m *MockRegistry) Providers() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Providers")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Providers indicates an expected call of Providers
+func (mr *MockRegistryMockRecorder) Providers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Providers", reflect.TypeOf((*MockRegistry)(nil).Providers))
+}
+
+// Register mocks base method
+func (m *MockRegistry) Register(arg0 string, arg1 oauth2.Config, arg2 func(string, oauth2.Token, []byte), arg3 ...oauther.Option) (oauther.OAuther, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Register", varargs...)
+	ret0, _ := ret[0].(oauther.OAuther)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register
+func (mr *MockRegistryMockRecorder) Register(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockRegistry)(nil).Register), varargs...)
+}
+
+// ServeHTTP mocks base method
+func (m *MockRegistry) ServeHTTP(arg0 http.ResponseWriter, arg1 *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ServeHTTP", arg0, arg1)
+}
+
+// ServeHTTP indicates an expected call of ServeHTTP
+func (mr *MockRegistryMockRecorder) ServeHTTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServeHTTP", reflect.TypeOf((*MockRegistry)(nil).ServeHTTP), arg0, arg1)
+}
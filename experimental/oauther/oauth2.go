@@ -4,9 +4,13 @@
 package oauther
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/mattermost/mattermost-server/v5/model"
 	"golang.org/x/oauth2"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
@@ -32,24 +36,52 @@ const (
 	completeURL = "/complete"
 )
 
+// MaxStorePrefixLength is the longest store prefix whose keys fit in the KVStore, which limits keys
+// to 50 characters. The longest key is the refresh lock, "mutex_" + prefix + "refresh_" + user ID.
+const MaxStorePrefixLength = model.KEY_VALUE_KEY_MAX_RUNES - len("mutex_") - len("refresh_") - 26
+
 // OAuther defines an object able to perform the OAuth flow.
 type OAuther interface {
 	// GetToken returns the oauth token for userID, or error if it does not exist or there is any store error.
 	GetToken(userID string) (*oauth2.Token, error)
+	// Client returns an HTTP client authenticated with the token of userID, refreshing and storing it when it expires.
+	// Returns ErrNotConnected if the user has no token.
+	Client(ctx context.Context, userID string) (*http.Client, error)
 	// GetConnectURL returns the URL to reach in order to start the OAuth flow.
 	GetConnectURL() string
-	// Deauthorize removes the token for userID. Return error if there is any store error.
+	// GetConnectURLWithScopes returns the URL to reach in order to request more scopes than the ones already granted.
+	GetConnectURLWithScopes(scopes ...string) string
+	// GetTokenInfo returns the provider and scopes the token of userID was issued for, or nil if there is no token.
+	GetTokenInfo(userID string) (*TokenInfo, error)
+	// ProviderID returns the ID the OAuther was registered with in a Registry, or an empty string.
+	ProviderID() string
+	// Deauthorize removes the token for userID, revoking it first if a RevokeURL is set. Return error if there is any
+	// store error.
 	Deauthorize(userID string) error
+	// ListConnections returns the users with a token, with its expiry and scopes.
+	ListConnections() ([]Connection, error)
+	// DisconnectAll deauthorizes every connected user, e.g. after a security incident, and returns how many were.
+	DisconnectAll() (int, error)
 	// ServeHTTP implements http.Handler
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
 	// AddPayload stores some information to be returned after the flow is over
 	AddPayload(userID string, payload []byte) error
 }
 
+// TokenInfo records what a token was issued for.
+type TokenInfo struct {
+	Provider string
+	Scopes   []string
+}
+
 type oAuther struct {
+	providerID            string
 	pluginURL             string
 	config                oauth2.Config
 	onConnect             func(userID string, token oauth2.Token, payload []byte)
+	onReconnectNeeded     func(userID string, err error)
+	eventListener         func(event Event)
+	revokeURL             string
 	store                 common.KVStore
 	logger                logger.Logger
 	storePrefix           string
@@ -57,6 +89,7 @@ type oAuther struct {
 	connectedString       string
 	oAuth2StateTimeToLive time.Duration
 	payloadTimeToLive     time.Duration
+	pkce                  bool
 }
 
 /*
@@ -72,7 +105,8 @@ New creates a new OAuther.
 
 - l Logger: A logger to log errors during authorization.
 
-- options: Optional options for the OAuther. Available options are StorePrefix, OAuthURL, ConnectedString and OAuth2StateTimeToLive.
+- options: Optional options for the OAuther. Available options are StorePrefix, OAuthURL, ConnectedString, OAuth2StateTimeToLive, PayloadTimeToLive, ReconnectNeeded,
+PKCE, EventListener and RevokeURL.
 */
 func New(
 	pluginURL string,
@@ -117,7 +151,8 @@ NewFromClient creates a new OAuther from the plugin api client.
 
 - l Logger: A logger to log errors during authorization.
 
-- options: Optional options for the OAuther. Available options are StorePrefix, OAuthURL, ConnectedString and OAuth2StateTimeToLive.
+- options: Optional options for the OAuther. Available options are StorePrefix, OAuthURL, ConnectedString, OAuth2StateTimeToLive, PayloadTimeToLive, ReconnectNeeded,
+PKCE, EventListener and RevokeURL.
 */
 func NewFromClient(
 	client *pluginapi.Client,
@@ -140,6 +175,23 @@ func (o *oAuther) GetConnectURL() string {
 	return o.pluginURL + o.oAuthURL + "/connect"
 }
 
+func (o *oAuther) GetConnectURLWithScopes(scopes ...string) string {
+	return o.GetConnectURL() + "?" + url.Values{"scope": {strings.Join(scopes, " ")}}.Encode()
+}
+
+func (o *oAuther) ProviderID() string {
+	return o.providerID
+}
+
+func (o *oAuther) GetTokenInfo(userID string) (*TokenInfo, error) {
+	var info *TokenInfo
+	err := o.store.Get(o.getTokenInfoKey(userID), &info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
 func (o *oAuther) GetToken(userID string) (*oauth2.Token, error) {
 	var token *oauth2.Token
 	err := o.store.Get(o.getTokenKey(userID), &token)
@@ -153,6 +205,10 @@ func (o *oAuther) getTokenKey(userID string) string {
 	return o.storePrefix + "token_" + userID
 }
 
+func (o *oAuther) getTokenInfoKey(userID string) string {
+	return o.storePrefix + "tokeninfo_" + userID
+}
+
 func (o *oAuther) getStateKey(userID string) string {
 	return o.storePrefix + "state_" + userID
 }
@@ -161,15 +217,6 @@ func (o *oAuther) getPayloadKey(userID string) string {
 	return o.storePrefix + "payload_" + userID
 }
 
-func (o *oAuther) Deauthorize(userID string) error {
-	err := o.store.Delete(o.getTokenKey(userID))
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (o *oAuther) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
 	case o.oAuthURL + connectURL:
@@ -0,0 +1,156 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package oauther
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/common"
+)
+
+// ErrNotConnected is returned when the user has no token.
+var ErrNotConnected = errors.New("user not connected")
+
+func (o *oAuther) Client(ctx context.Context, userID string) (*http.Client, error) {
+	token, err := o.GetToken(userID)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrNotConnected
+	}
+
+	ts := &refreshingTokenSource{
+		o:      o,
+		ctx:    ctx,
+		userID: userID,
+	}
+
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(token, ts)), nil
+}
+
+// refreshingTokenSource returns the stored token of the user, refreshing it when it has expired.
+type refreshingTokenSource struct {
+	o      *oAuther
+	ctx    context.Context
+	userID string
+}
+
+func (ts *refreshingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := ts.o.GetToken(ts.userID)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrNotConnected
+	}
+	if token.Valid() {
+		return token, nil
+	}
+
+	// Other goroutines or nodes may be refreshing the same token. The refresh token may be
+	// single use, so only one of them must reach the provider.
+	mutex, err := cluster.NewMutex(&kvMutexAPI{store: ts.o.store, logger: ts.o.logger}, ts.o.getRefreshLockKey(ts.userID))
+	if err != nil {
+		return nil, err
+	}
+	err = mutex.LockWithContext(ts.ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot lock the token refresh")
+	}
+	defer mutex.Unlock()
+
+	token, err = ts.o.GetToken(ts.userID)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrNotConnected
+	}
+	if token.Valid() {
+		return token, nil
+	}
+
+	newToken, err := ts.o.config.TokenSource(ts.ctx, token).Token()
+	if err != nil {
+		ts.o.refreshFailed(ts.userID, err)
+		return nil, errors.Wrap(err, "cannot refresh the token")
+	}
+
+	// The provider may have invalidated the old refresh token, so the new one must not be lost.
+	ok, err := ts.o.store.Set(ts.o.getTokenKey(ts.userID), newToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot store the refreshed token")
+	}
+	if !ok {
+		return nil, errors.New("cannot store the refreshed token without error")
+	}
+
+	ts.o.sendEvent(EventRefreshed, ts.userID, nil)
+	return newToken, nil
+}
+
+// refreshFailed removes the token if the provider rejected it, e.g. because it was revoked, and
+// lets the plugin ask the user to connect again.
+func (o *oAuther) refreshFailed(userID string, err error) {
+	o.logger.Warnf("cannot refresh the token of user %s, err=%s", userID, err.Error())
+	o.sendEvent(EventRefreshFailed, userID, err)
+
+	if _, ok := err.(*oauth2.RetrieveError); ok {
+		deleteErr := o.Deauthorize(userID)
+		if deleteErr != nil {
+			o.logger.Errorf("cannot remove the rejected token of user %s, err=%s", userID, deleteErr.Error())
+		}
+	}
+
+	if o.onReconnectNeeded != nil {
+		o.onReconnectNeeded(userID, err)
+	}
+}
+
+func (o *oAuther) getRefreshLockKey(userID string) string {
+	return o.storePrefix + "refresh_" + userID
+}
+
+// kvMutexAPI lets cluster mutexes use the KVStore of the OAuther.
+type kvMutexAPI struct {
+	store  common.KVStore
+	logger logger.Logger
+}
+
+func (a *kvMutexAPI) KVSetWithOptions(key string, value []byte, options model.PluginKVSetOptions) (bool, *model.AppError) {
+	setOptions := []pluginapi.KVSetOption{}
+	if options.Atomic {
+		setOptions = append(setOptions, pluginapi.SetAtomic(options.OldValue))
+	}
+	if options.ExpireInSeconds > 0 {
+		setOptions = append(setOptions, pluginapi.SetExpiry(time.Duration(options.ExpireInSeconds)*time.Second))
+	}
+
+	ok, err := a.store.Set(key, value, setOptions...)
+	if err != nil {
+		return false, model.NewAppError("KVSetWithOptions", "oauther.kv_set.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return ok, nil
+}
+
+func (a *kvMutexAPI) LogError(msg string, keyValuePairs ...interface{}) {
+	lc := logger.LogContext{}
+	for i := 0; i+1 < len(keyValuePairs); i += 2 {
+		if key, ok := keyValuePairs[i].(string); ok {
+			lc[key] = keyValuePairs[i+1]
+		}
+	}
+	a.logger.With(lc).Errorf("%s", msg)
+}
@@ -0,0 +1,238 @@
+package oauther
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+)
+
+// setupKV returns a plugin API keeping the KV store in memory, honoring atomic sets and the length limit of the keys.
+func setupKV() *plugintest.API {
+	var mu sync.Mutex
+	kv := map[string][]byte{}
+
+	// The server rejects keys longer than the limit.
+	checkKey := func(key string) *model.AppError {
+		if utf8.RuneCountInString(key) > model.KEY_VALUE_KEY_MAX_RUNES {
+			return model.NewAppError("KVSetWithOptions", "key too long", nil, key, http.StatusBadRequest)
+		}
+		return nil
+	}
+
+	api := &plugintest.API{}
+	api.On("KVGet", mock.AnythingOfType("string")).Return(
+		func(key string) []byte {
+			mu.Lock()
+			defer mu.Unlock()
+			return kv[key]
+		},
+		checkKey,
+	)
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(
+		func(key string, value []byte, options model.PluginKVSetOptions) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if checkKey(key) != nil {
+				return false
+			}
+			if options.Atomic && !bytes.Equal(kv[key], options.OldValue) {
+				return false
+			}
+			if value == nil {
+				delete(kv, key)
+			} else {
+				kv[key] = value
+			}
+			return true
+		},
+		func(key string, value []byte, options model.PluginKVSetOptions) *model.AppError {
+			return checkKey(key)
+		},
+	)
+	api.On("KVList", mock.AnythingOfType("int"), mock.AnythingOfType("int")).Return(
+		func(page, perPage int) []string {
+			mu.Lock()
+			defer mu.Unlock()
+			keys := []string{}
+			for key := range kv {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if page*perPage >= len(keys) {
+				return []string{}
+			}
+			keys = keys[page*perPage:]
+			if len(keys) > perPage {
+				keys = keys[:perPage]
+			}
+			return keys
+		},
+		nil,
+	)
+	api.On("KVDelete", mock.AnythingOfType("string")).Return(
+		func(key string) *model.AppError {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(kv, key)
+			return nil
+		},
+	)
+
+	return api
+}
+
+func newTokenServer(t *testing.T, refreshes *int, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		*refreshes++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "new-access",
+			"refresh_token": "new-refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+}
+
+func TestClient(t *testing.T) {
+	userID := model.NewId()
+	expired := &oauth2.Token{
+		AccessToken:  "old-access",
+		RefreshToken: "old-refresh",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(-time.Hour),
+	}
+
+	setup := func(server *httptest.Server, options ...Option) (OAuther, *pluginapi.Client) {
+		client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+		o := New("https://example.com/plugins/test", oauth2.Config{
+			ClientID: "client",
+			Endpoint: oauth2.Endpoint{TokenURL: server.URL},
+		}, nil, &client.KV, logger.NewNilLogger(), options...)
+		return o, client
+	}
+
+	t.Run("not connected", func(t *testing.T) {
+		var refreshes int
+		server := newTokenServer(t, &refreshes, http.StatusOK)
+		defer server.Close()
+		o, _ := setup(server)
+
+		_, err := o.Client(context.Background(), userID)
+		require.Equal(t, ErrNotConnected, err)
+	})
+
+	t.Run("refresh and store", func(t *testing.T) {
+		var refreshes int
+		server := newTokenServer(t, &refreshes, http.StatusOK)
+		defer server.Close()
+		o, client := setup(server)
+
+		_, err := client.KV.Set(DefaultStorePrefix+"token_"+userID, expired)
+		require.NoError(t, err)
+
+		var authorization string
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+		}))
+		defer api.Close()
+
+		httpClient, err := o.Client(context.Background(), userID)
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			resp, err := httpClient.Get(api.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+
+		assert.Equal(t, "Bearer new-access", authorization)
+		assert.Equal(t, 1, refreshes)
+
+		token, err := o.GetToken(userID)
+		require.NoError(t, err)
+		assert.Equal(t, "new-refresh", token.RefreshToken)
+
+		// Other clients use the stored token without refreshing it again.
+		httpClient, err = o.Client(context.Background(), userID)
+		require.NoError(t, err)
+		resp, err := httpClient.Get(api.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, 1, refreshes)
+	})
+
+	t.Run("store fails", func(t *testing.T) {
+		var refreshes int
+		server := newTokenServer(t, &refreshes, http.StatusOK)
+		defer server.Close()
+
+		api := setupKV()
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		o := New("https://example.com/plugins/test", oauth2.Config{
+			ClientID: "client",
+			Endpoint: oauth2.Endpoint{TokenURL: server.URL},
+		}, nil, &client.KV, logger.NewNilLogger())
+
+		_, err := client.KV.Set(DefaultStorePrefix+"token_"+userID, expired)
+		require.NoError(t, err)
+
+		// The refreshed token is not stored, without an error.
+		call := api.On("KVSetWithOptions", DefaultStorePrefix+"token_"+userID, mock.Anything, mock.Anything).Return(false, nil)
+		api.ExpectedCalls = append([]*mock.Call{call}, api.ExpectedCalls[:len(api.ExpectedCalls)-1]...)
+
+		httpClient, err := o.Client(context.Background(), userID)
+		require.NoError(t, err)
+		_, err = httpClient.Get(server.URL)
+		require.Error(t, err)
+		assert.Equal(t, 1, refreshes)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		var refreshes int
+		server := newTokenServer(t, &refreshes, http.StatusBadRequest)
+		defer server.Close()
+
+		var reconnectUserID string
+		o, client := setup(server, ReconnectNeeded(func(userID string, err error) {
+			reconnectUserID = userID
+		}))
+
+		_, err := client.KV.Set(DefaultStorePrefix+"token_"+userID, expired)
+		require.NoError(t, err)
+
+		httpClient, err := o.Client(context.Background(), userID)
+		require.NoError(t, err)
+		_, err = httpClient.Get(server.URL)
+		require.Error(t, err)
+
+		assert.Equal(t, userID, reconnectUserID)
+		token, err := o.GetToken(userID)
+		require.NoError(t, err)
+		assert.Nil(t, token)
+	})
+}
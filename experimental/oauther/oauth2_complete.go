@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+
+	"golang.org/x/oauth2"
 )
 
 func (o *oAuther) oauth2Complete(w http.ResponseWriter, r *http.Request) {
@@ -29,7 +31,7 @@ func (o *oAuther) oauth2Complete(w http.ResponseWriter, r *http.Request) {
 	}
 	state := r.URL.Query().Get("state")
 
-	var storedState string
+	var storedState oAuthState
 	err := o.store.Get(o.getStateKey(authedUserID), &storedState)
 	if err != nil {
 		o.logger.Warnf("oauth2Complete: cannot get state, err=%s", err.Error())
@@ -37,9 +39,9 @@ func (o *oAuther) oauth2Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if storedState != state {
+	if storedState.State == "" || storedState.State != state {
 		o.logger.Debugf("oauth2Complete: state mismatch")
-		o.logger.Debugf("received state '%s'; expected state '%s%", state, storedState)
+		o.logger.Debugf("received state '%s'; expected state '%s'", state, storedState.State)
 		http.Error(w, "Not authorized", http.StatusUnauthorized)
 		return
 	}
@@ -51,8 +53,15 @@ func (o *oAuther) oauth2Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	config := o.config
+	config.Scopes = storedState.Scopes
+	options := []oauth2.AuthCodeOption{}
+	if storedState.Verifier != "" {
+		options = append(options, oauth2.SetAuthURLParam("code_verifier", storedState.Verifier))
+	}
+
 	ctx := context.Background()
-	token, err := o.config.Exchange(ctx, code)
+	token, err := config.Exchange(ctx, code, options...)
 	if err != nil {
 		o.logger.Warnf("oauth2Complete: could not generate token, err=%s", err.Error())
 		http.Error(w, "Not authorized", http.StatusUnauthorized)
@@ -79,6 +88,17 @@ func (o *oAuther) oauth2Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	info := TokenInfo{
+		Provider: o.providerID,
+		Scopes:   grantedScopes(token, storedState.Scopes),
+	}
+	_, err = o.store.Set(o.getTokenInfoKey(userID), info)
+	if err != nil {
+		o.logger.Errorf("oauth2Complete: cannot store the token info, err=%s", err.Error())
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	html := fmt.Sprintf(`
 		<!DOCTYPE html>
 		<html>
@@ -99,7 +119,17 @@ func (o *oAuther) oauth2Complete(w http.ResponseWriter, r *http.Request) {
 		o.logger.Errorf("oauth2Complete: error writing response, err=%s", err.Error())
 	}
 
+	o.sendEvent(EventConnected, userID, nil)
+
 	if o.onConnect != nil {
 		o.onConnect(userID, *token, payload)
 	}
 }
+
+// grantedScopes returns the scopes the provider says it granted, which may differ from the requested ones.
+func grantedScopes(token *oauth2.Token, requested []string) []string {
+	if scope, ok := token.Extra("scope").(string); ok && scope != "" {
+		return strings.Fields(scope)
+	}
+	return requested
+}
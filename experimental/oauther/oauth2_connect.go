@@ -4,8 +4,12 @@
 package oauther
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"golang.org/x/oauth2"
@@ -13,6 +17,13 @@ import (
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 )
 
+// oAuthState is stored during the OAuth flow, to check the state and complete the exchange.
+type oAuthState struct {
+	State    string
+	Verifier string
+	Scopes   []string
+}
+
 func (o *oAuther) oauth2Connect(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -25,14 +36,83 @@ func (o *oAuther) oauth2Connect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state := fmt.Sprintf("%v_%v", model.NewId()[0:15], userID)
-	_, err := o.store.Set(o.getStateKey(userID), state, pluginapi.SetExpiry(o.oAuth2StateTimeToLive))
+	scopes, err := o.requestedScopes(userID, r.URL.Query().Get("scope"))
+	if err != nil {
+		o.logger.Errorf("oauth2Connect: failed to get token info, err=%s", err.Error())
+		http.Error(w, "failed to get token info", http.StatusInternalServerError)
+		return
+	}
+
+	state := oAuthState{
+		State:  fmt.Sprintf("%v_%v", model.NewId()[0:15], userID),
+		Scopes: scopes,
+	}
+	options := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline}
+	if o.pkce {
+		state.Verifier, err = newPKCEVerifier()
+		if err != nil {
+			o.logger.Errorf("oauth2Connect: failed to generate verifier, err=%s", err.Error())
+			http.Error(w, "failed to generate verifier", http.StatusInternalServerError)
+			return
+		}
+		challenge := sha256.Sum256([]byte(state.Verifier))
+		options = append(options,
+			oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		)
+	}
+
+	_, err = o.store.Set(o.getStateKey(userID), state, pluginapi.SetExpiry(o.oAuth2StateTimeToLive))
 	if err != nil {
 		o.logger.Errorf("oauth2Connect: failed to store state, err=%s", err.Error())
 		http.Error(w, "failed to store token state", http.StatusInternalServerError)
 		return
 	}
 
-	redirectURL := o.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	config := o.config
+	config.Scopes = scopes
+	redirectURL := config.AuthCodeURL(state.State, options...)
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
+
+// requestedScopes returns the scopes of the configuration, the ones already granted to the user and the extra ones
+// requested, separated by spaces or commas.
+func (o *oAuther) requestedScopes(userID, extra string) ([]string, error) {
+	scopes := append([]string{}, o.config.Scopes...)
+	if extra == "" {
+		return scopes, nil
+	}
+
+	info, err := o.GetTokenInfo(userID)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		scopes = append(scopes, info.Scopes...)
+	}
+
+	scopes = append(scopes, strings.FieldsFunc(extra, func(r rune) bool {
+		return r == ' ' || r == ','
+	})...)
+
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+
+	return unique, nil
+}
+
+func newPKCEVerifier() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
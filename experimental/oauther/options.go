@@ -3,7 +3,8 @@ package oauther
 import "time"
 
 // Option defines each option that can be passed in the creation of the OAuther.
-// Options functions available are OAuthURL, StorePrefix, ConnectedString and OAuth2StateTimeToLive and PayloadTimeToLive.
+// Options functions available are OAuthURL, StorePrefix, ConnectedString, OAuth2StateTimeToLive, PayloadTimeToLive
+// ReconnectNeeded, PKCE, EventListener and RevokeURL.
 type Option func(*oAuther)
 
 // OAuthURL defines the URL the OAuther will use to register its endpoints.
@@ -14,7 +15,8 @@ func OAuthURL(url string) Option {
 	}
 }
 
-// StorePrefix defines the prefix the OAuther will use to store information in the KVStore.
+// StorePrefix defines the prefix the OAuther will use to store information in the KVStore. It must
+// not be longer than MaxStorePrefixLength.
 // Defaults to "oauth_".
 func StorePrefix(prefix string) Option {
 	return func(o *oAuther) {
@@ -45,3 +47,33 @@ func PayloadTimeToLive(ttl time.Duration) Option {
 		o.payloadTimeToLive = ttl
 	}
 }
+
+// ReconnectNeeded defines what to do when the token of a user cannot be refreshed, e.g. asking the user to connect again.
+// If the provider rejected the token, e.g. because it was revoked, the token is removed before calling it.
+func ReconnectNeeded(f func(userID string, err error)) Option {
+	return func(o *oAuther) {
+		o.onReconnectNeeded = f
+	}
+}
+
+// PKCE adds a Proof Key for Code Exchange to the OAuth flow, as required by providers for public clients.
+func PKCE() Option {
+	return func(o *oAuther) {
+		o.pkce = true
+	}
+}
+
+// EventListener defines what to do on each connection lifecycle event, e.g. tracking it.
+func EventListener(f func(event Event)) Option {
+	return func(o *oAuther) {
+		o.eventListener = f
+	}
+}
+
+// RevokeURL defines the endpoint of the provider where tokens are revoked when a user is deauthorized.
+// By default, tokens are only removed from the KVStore.
+func RevokeURL(url string) Option {
+	return func(o *oAuther) {
+		o.revokeURL = url
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package oauther
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/common"
+)
+
+// Registry holds several OAuthers of a plugin, each identified by a provider ID, e.g. one per Jira instance. A user
+// can connect to any of them, and their tokens are stored separately.
+type Registry interface {
+	// Register creates the OAuther of a provider. Its endpoints are served under the OAuth URL of the registry followed
+	// by the provider ID, and its data is stored under its own prefix, derived from a hash of the provider ID so that
+	// the keys fit in the KVStore whatever the length of the ID.
+	Register(providerID string, oAuthConfig oauth2.Config, onConnect func(userID string, token oauth2.Token, payload []byte), options ...Option) (OAuther, error)
+	// Get returns the OAuther of the provider, or nil if there is none.
+	Get(providerID string) OAuther
+	// Providers returns the IDs of all providers, in the order they were registered.
+	Providers() []string
+	// ConnectedProviders returns the IDs of the providers userID has a token for.
+	ConnectedProviders(userID string) ([]string, error)
+	// ListConnections returns the connections of all providers.
+	ListConnections() ([]Connection, error)
+	// DisconnectAll deauthorizes every connected user of every provider, and returns how many connections were removed.
+	DisconnectAll() (int, error)
+	// ServeHTTP implements http.Handler, routing the requests to the OAuther of the provider.
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type registry struct {
+	pluginURL string
+	oAuthURL  string
+	store     common.KVStore
+	logger    logger.Logger
+	providers []string
+	byID      map[string]OAuther
+}
+
+// NewRegistry creates a new Registry serving the endpoints of every provider under oAuthURL, or DefaultOAuthURL if
+// empty.
+func NewRegistry(pluginURL, oAuthURL string, store common.KVStore, l logger.Logger) Registry {
+	if oAuthURL == "" {
+		oAuthURL = DefaultOAuthURL
+	}
+
+	return &registry{
+		pluginURL: pluginURL,
+		oAuthURL:  oAuthURL,
+		store:     store,
+		logger:    l,
+		byID:      map[string]OAuther{},
+	}
+}
+
+func (r *registry) Register(
+	providerID string,
+	oAuthConfig oauth2.Config,
+	onConnect func(userID string, token oauth2.Token, payload []byte),
+	options ...Option,
+) (OAuther, error) {
+	if providerID == "" || strings.Contains(providerID, "/") {
+		return nil, errors.Errorf("invalid provider id %q", providerID)
+	}
+
+	if _, ok := r.byID[providerID]; ok {
+		return nil, errors.Errorf("provider %s is already registered", providerID)
+	}
+
+	options = append([]Option{StorePrefix(providerStorePrefix(providerID))}, options...)
+	options = append(options, OAuthURL(r.oAuthURL+"/"+providerID), func(o *oAuther) {
+		o.providerID = providerID
+	})
+	o := New(r.pluginURL, oAuthConfig, onConnect, r.store, r.logger, options...)
+
+	prefix := o.(*oAuther).storePrefix
+	if utf8.RuneCountInString(prefix) > MaxStorePrefixLength {
+		return nil, errors.Errorf("store prefix %q of provider %s is longer than %d characters", prefix, providerID, MaxStorePrefixLength)
+	}
+	for _, id := range r.providers {
+		if r.byID[id].(*oAuther).storePrefix == prefix {
+			return nil, errors.Errorf("providers %s and %s have the same store prefix %q", id, providerID, prefix)
+		}
+	}
+
+	r.providers = append(r.providers, providerID)
+	r.byID[providerID] = o
+
+	return o, nil
+}
+
+// providerStorePrefix returns the store prefix of a provider, e.g. "o1a2b3c4d_". Provider IDs can be of any length,
+// so they are hashed to keep the keys of the provider within the limit of the KVStore.
+func providerStorePrefix(providerID string) string {
+	hash := sha256.Sum256([]byte(providerID))
+	return fmt.Sprintf("o%x_", hash[:4])
+}
+
+func (r *registry) Get(providerID string) OAuther {
+	return r.byID[providerID]
+}
+
+func (r *registry) Providers() []string {
+	return r.providers
+}
+
+func (r *registry) ConnectedProviders(userID string) ([]string, error) {
+	ids := []string{}
+	for _, id := range r.providers {
+		token, err := r.byID[id].GetToken(userID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get the token of provider %s", id)
+		}
+		if token != nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *registry) ListConnections() ([]Connection, error) {
+	connections := []Connection{}
+	for _, id := range r.providers {
+		providerConnections, err := r.byID[id].ListConnections()
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot list the connections of provider %s", id)
+		}
+		connections = append(connections, providerConnections...)
+	}
+
+	return connections, nil
+}
+
+func (r *registry) DisconnectAll() (int, error) {
+	total := 0
+	for _, id := range r.providers {
+		disconnected, err := r.byID[id].DisconnectAll()
+		total += disconnected
+		if err != nil {
+			return total, errors.Wrapf(err, "cannot disconnect the users of provider %s", id)
+		}
+	}
+
+	return total, nil
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, r.oAuthURL+"/")
+	providerID := strings.SplitN(path, "/", 2)[0]
+
+	o, ok := r.byID[providerID]
+	if !ok || path == req.URL.Path {
+		http.NotFound(w, req)
+		return
+	}
+
+	o.ServeHTTP(w, req)
+}
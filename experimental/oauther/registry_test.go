@@ -0,0 +1,173 @@
+package oauther
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+)
+
+func TestRegistry(t *testing.T) {
+	userID := model.NewId()
+
+	var verifier string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "code", r.Form.Get("code"))
+		verifier = r.Form.Get("code_verifier")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"scope":        "read write",
+		})
+	}))
+	defer server.Close()
+
+	config := oauth2.Config{
+		ClientID: "client",
+		Scopes:   []string{"read"},
+		Endpoint: oauth2.Endpoint{AuthURL: server.URL + "/authorize", TokenURL: server.URL + "/token"},
+	}
+
+	client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+	r := NewRegistry("https://example.com/plugins/test", "", &client.KV, logger.NewNilLogger())
+
+	jira, err := r.Register("jira-1", config, nil, PKCE())
+	require.NoError(t, err)
+	other, err := r.Register("jira-2", config, nil)
+	require.NoError(t, err)
+
+	_, err = r.Register("jira-1", config, nil)
+	require.Error(t, err)
+	_, err = r.Register("a/b", config, nil)
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"jira-1", "jira-2"}, r.Providers())
+	assert.Equal(t, "https://example.com/plugins/test/oauth2/jira-1/connect", jira.GetConnectURL())
+	assert.Equal(t, "jira-2", other.ProviderID())
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Mattermost-User-ID", userID)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/oauth2/jira-1/connect?scope=write")
+	require.Equal(t, http.StatusFound, w.Code)
+	redirect, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	query := redirect.Query()
+	assert.Equal(t, "read write", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "https://example.com/plugins/test/oauth2/jira-1/complete", query.Get("redirect_uri"))
+
+	w = serve("/oauth2/jira-1/complete?code=code&state=" + url.QueryEscape(query.Get("state")))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	challenge := sha256.Sum256([]byte(verifier))
+	assert.Equal(t, query.Get("code_challenge"), base64.RawURLEncoding.EncodeToString(challenge[:]))
+
+	info, err := jira.GetTokenInfo(userID)
+	require.NoError(t, err)
+	assert.Equal(t, &TokenInfo{Provider: "jira-1", Scopes: []string{"read", "write"}}, info)
+
+	connected, err := r.ConnectedProviders(userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jira-1"}, connected)
+
+	assert.Equal(t, http.StatusNotFound, serve("/oauth2/unknown/connect").Code)
+	assert.Equal(t, http.StatusNotFound, serve("/other/jira-1/connect").Code)
+}
+
+func TestRegistryKeyLength(t *testing.T) {
+	userID := model.NewId()
+	providerID := "jira-server.a-rather-long-hostname.example.com"
+
+	var refreshes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		token := map[string]interface{}{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		}
+		if r.Form.Get("grant_type") == "refresh_token" {
+			refreshes++
+		} else {
+			// The first token is already expired, to be refreshed by the client.
+			token["expires_in"] = -1
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(token)
+	}))
+	defer server.Close()
+
+	config := oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{AuthURL: server.URL + "/authorize", TokenURL: server.URL + "/token"},
+	}
+
+	api := setupKV()
+	client := pluginapi.NewClient(api, &plugintest.Driver{})
+	_, err := pluginapi.NewClient(setupKV(), &plugintest.Driver{}).KV.Set(strings.Repeat("k", model.KEY_VALUE_KEY_MAX_RUNES+1), "value")
+	require.Error(t, err, "the KV store must reject long keys")
+
+	r := NewRegistry("https://example.com/plugins/test", "", &client.KV, logger.NewNilLogger())
+	_, err = r.Register("other", config, nil, StorePrefix("a_prefix_too_long_"))
+	require.Error(t, err)
+
+	o, err := r.Register(providerID, config, nil, PKCE())
+	require.NoError(t, err)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Mattermost-User-ID", userID)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/oauth2/" + providerID + "/connect")
+	require.Equal(t, http.StatusFound, w.Code)
+	redirect, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+
+	w = serve("/oauth2/" + providerID + "/complete?code=code&state=" + url.QueryEscape(redirect.Query().Get("state")))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	httpClient, err := o.Client(context.Background(), userID)
+	require.NoError(t, err)
+	resp, err := httpClient.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 1, refreshes)
+
+	for _, call := range api.Calls {
+		if call.Method == "KVList" {
+			continue
+		}
+		key := call.Arguments.String(0)
+		assert.LessOrEqual(t, utf8.RuneCountInString(key), model.KEY_VALUE_KEY_MAX_RUNES, key)
+	}
+}
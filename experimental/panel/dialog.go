@@ -0,0 +1,137 @@
+package panel
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/common"
+	"github.com/mattermost/mattermost-plugin-api/experimental/panel/settings"
+)
+
+// dialogPath is appended to the setting handler URL to receive dialog submissions.
+const dialogPath = "/dialog"
+
+func (p *panel) dialogAttachment() *model.SlackAttachment {
+	if p.dialogOpener == nil {
+		return nil
+	}
+
+	return &model.SlackAttachment{
+		Actions: []*model.PostAction{{
+			Name: p.dialogButtonLabel,
+			Integration: &model.PostActionIntegration{
+				URL: p.pluginURL + p.settingHandler,
+				Context: map[string]interface{}{
+					settings.ContextDialogKey: true,
+				},
+			},
+		}},
+	}
+}
+
+// dialogSettings returns the enabled settings that can be edited through the dialog.
+func (p *panel) dialogSettings(ownerID string) []settings.DialogSetting {
+	dialogSettings := []settings.DialogSetting{}
+	for _, key := range p.settingKeys {
+		s, ok := p.settings[key].(settings.DialogSetting)
+		if !ok || p.isSettingDisabled(ownerID, s) {
+			continue
+		}
+		dialogSettings = append(dialogSettings, s)
+	}
+
+	return dialogSettings
+}
+
+// openDialog opens the dialog for the settings of the owner. Scoped panels keep the owner ID in
+// the dialog state.
+func (p *panel) openDialog(ownerID, triggerID string) error {
+	if p.dialogOpener == nil {
+		return errors.New("dialogs are not enabled for this panel")
+	}
+
+	elements := []model.DialogElement{}
+	for _, s := range p.dialogSettings(ownerID) {
+		element, err := s.GetDialogElement(ownerID)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get dialog element for setting %s", s.GetID())
+		}
+		elements = append(elements, *element)
+	}
+
+	state := ""
+	if p.scope != "" {
+		state = ownerID
+	}
+
+	return p.dialogOpener.OpenInteractiveDialog(model.OpenDialogRequest{
+		TriggerId: triggerID,
+		URL:       p.pluginURL + p.settingHandler + dialogPath,
+		Dialog: model.Dialog{
+			Title:       p.dialogTitle,
+			Elements:    elements,
+			SubmitLabel: p.dialogSubmitLabel,
+			State:       state,
+		},
+	})
+}
+
+func (p *panel) submitDialog(userID, ownerID string, submission map[string]interface{}) (map[string]string, error) {
+	dialogSettings := p.dialogSettings(ownerID)
+
+	elements := []model.DialogElement{}
+	for _, s := range dialogSettings {
+		element, err := s.GetDialogElement(ownerID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get dialog element for setting %s", s.GetID())
+		}
+		elements = append(elements, *element)
+	}
+
+	errs := common.ValidateDialogSubmission(elements, submission)
+	for _, s := range dialogSettings {
+		if _, ok := errs[s.GetID()]; ok {
+			continue
+		}
+
+		if message := s.ValidateDialogValue(submission[s.GetID()]); message != "" {
+			if errs == nil {
+				errs = map[string]string{}
+			}
+			errs[s.GetID()] = message
+		}
+	}
+	if len(errs) > 0 {
+		return errs, nil
+	}
+
+	for _, s := range dialogSettings {
+		value := submission[s.GetID()]
+		if value == nil {
+			value = ""
+		}
+
+		err := s.Set(ownerID, value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot set setting %s", s.GetID())
+		}
+	}
+
+	postID, err := p.store.GetPanelPostID(p.getPostKey(userID, ownerID))
+	if err != nil || postID == "" {
+		return nil, nil
+	}
+
+	post, err := p.toPost(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	post.Id = postID
+
+	err = p.poster.UpdatePost(post)
+	if err != nil {
+		p.logger.Errorf("could not update the panel post, err=%s", err.Error())
+	}
+
+	return nil, nil
+}
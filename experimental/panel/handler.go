@@ -11,16 +11,22 @@ import (
 )
 
 type handler struct {
-	panel Panel
+	panel ScopedPanel
 }
 
 func Init(r *mux.Router, panel Panel) {
+	InitScoped(r, userPanel{panel})
+}
+
+// InitScoped registers the handlers of a channel or team settings panel.
+func InitScoped(r *mux.Router, panel ScopedPanel) {
 	sh := &handler{
 		panel: panel,
 	}
 
 	panelRouter := r.PathPrefix("/").Subrouter()
 	panelRouter.HandleFunc(panel.URL(), sh.handleAction).Methods(http.MethodPost)
+	panelRouter.HandleFunc(panel.URL()+dialogPath, sh.handleDialog).Methods(http.MethodPost)
 }
 
 func (sh *handler) handleAction(w http.ResponseWriter, r *http.Request) {
@@ -36,12 +42,38 @@ func (sh *handler) handleAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scopeID, _ := request.Context[settings.ContextScopeIDKey].(string)
+
+	if dialog, _ := request.Context[settings.ContextDialogKey].(bool); dialog {
+		err := sh.panel.OpenDialog(mattermostUserID, scopeID, request.TriggerId)
+		if err != nil {
+			common.SlackAttachmentError(w, "Error: cannot open the dialog, "+err.Error())
+			return
+		}
+
+		response := model.PostActionIntegrationResponse{}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(response.ToJson())
+		return
+	}
+
 	id, ok := request.Context[settings.ContextIDKey]
 	if !ok {
 		common.SlackAttachmentError(w, "Error: missing setting id")
 		return
 	}
 
+	if reset, _ := request.Context[settings.ContextResetKey].(bool); reset {
+		err := sh.panel.Reset(mattermostUserID, scopeID, id.(string))
+		if err != nil {
+			common.SlackAttachmentError(w, "Error: cannot reset the property, "+err.Error())
+			return
+		}
+
+		sh.respondWithPanel(w, mattermostUserID, scopeID)
+		return
+	}
+
 	value, ok := request.Context[settings.ContextButtonValueKey]
 	if !ok {
 		value, ok = request.Context[settings.ContextOptionValueKey]
@@ -52,17 +84,53 @@ func (sh *handler) handleAction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	idString := id.(string)
-	err := sh.panel.Set(mattermostUserID, idString, value)
+	err := sh.panel.Set(mattermostUserID, scopeID, idString, value)
 	if err != nil {
 		common.SlackAttachmentError(w, "Error: cannot set the property, "+err.Error())
 		return
 	}
 
+	sh.respondWithPanel(w, mattermostUserID, scopeID)
+}
+
+func (sh *handler) respondWithPanel(w http.ResponseWriter, userID, scopeID string) {
 	response := model.PostActionIntegrationResponse{}
-	post, err := sh.panel.ToPost(mattermostUserID)
+	post, err := sh.panel.ToPost(userID, scopeID)
 	if err == nil {
 		response.Update = post
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_, _ = w.Write(response.ToJson())
 }
+
+func (sh *handler) handleDialog(w http.ResponseWriter, r *http.Request) {
+	mattermostUserID := r.Header.Get("Mattermost-User-ID")
+	if mattermostUserID == "" {
+		common.DialogError(w, "Error: Not authorized", nil)
+		return
+	}
+
+	request := model.SubmitDialogRequestFromJson(r.Body)
+	if request == nil {
+		common.DialogError(w, "Error: invalid request", nil)
+		return
+	}
+
+	if request.Cancelled {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	errs, err := sh.panel.SubmitDialog(mattermostUserID, request.State, request.Submission)
+	if err != nil {
+		common.DialogError(w, "Error: cannot set the settings, "+err.Error(), nil)
+		return
+	}
+
+	if len(errs) > 0 {
+		common.DialogError(w, "", errs)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
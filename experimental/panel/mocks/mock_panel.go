@@ -61,6 +61,20 @@ func (mr *MockPanelMockRecorder) GetSettingIDs() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettingIDs", reflect.TypeOf((*MockPanel)(nil).GetSettingIDs))
 }
 
+// OpenDialog mocks base method
+func (m *MockPanel) OpenDialog(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDialog", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OpenDialog indicates an expected call of OpenDialog
+func (mr *MockPanelMockRecorder) OpenDialog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDialog", reflect.TypeOf((*MockPanel)(nil).OpenDialog), arg0, arg1)
+}
+
 // Print mocks base method
 func (m *MockPanel) Print(arg0 string) {
 	m.ctrl.T.Helper()
@@ -73,6 +87,34 @@ func (mr *MockPanelMockRecorder) Print(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Print", reflect.TypeOf((*MockPanel)(nil).Print), arg0)
 }
 
+// Reset mocks base method
+func (m *MockPanel) Reset(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockPanelMockRecorder) Reset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockPanel)(nil).Reset), arg0, arg1)
+}
+
+// Schema mocks base method
+func (m *MockPanel) Schema() map[string]interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schema")
+	ret0, _ := ret[0].(map[string]interface{})
+	return ret0
+}
+
+// Schema indicates an expected call of Schema
+func (mr *MockPanelMockRecorder) Schema() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schema", reflect.TypeOf((*MockPanel)(nil).Schema))
+}
+
 // Set mocks base method
 func (m *MockPanel) Set(arg0, arg1 string, arg2 interface{}) error {
 	m.ctrl.T.Helper()
@@ -87,6 +129,21 @@ func (mr *MockPanelMockRecorder) Set(arg0, arg1, arg2 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockPanel)(nil).Set), arg0, arg1, arg2)
 }
 
+// SubmitDialog mocks base method
+func (m *MockPanel) SubmitDialog(arg0 string, arg1 map[string]interface{}) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitDialog", arg0, arg1)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitDialog indicates an expected call of SubmitDialog
+func (mr *MockPanelMockRecorder) SubmitDialog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitDialog", reflect.TypeOf((*MockPanel)(nil).SubmitDialog), arg0, arg1)
+}
+
 // ToPost mocks base method
 func (m *MockPanel) ToPost(arg0 string) (*model.Post, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,174 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-api/experimental/panel (interfaces: ScopedPanel)
+
+// Package mock_panel is a generated GoMock package.
+package mock_panel
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/mattermost/mattermost-server/v5/model"
+	reflect "reflect"
+)
+
+// MockScopedPanel is a mock of ScopedPanel interface
+type MockScopedPanel struct {
+	ctrl     *gomock.Controller
+	recorder *MockScopedPanelMockRecorder
+}
+
+// MockScopedPanelMockRecorder is the mock recorder for MockScopedPanel
+type MockScopedPanelMockRecorder struct {
+	mock *MockScopedPanel
+}
+
+// NewMockScopedPanel creates a new mock instance
+func NewMockScopedPanel(ctrl *gomock.Controller) *MockScopedPanel {
+	mock := &MockScopedPanel{ctrl: ctrl}
+	mock.recorder = &MockScopedPanelMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockScopedPanel) EXPECT() *MockScopedPanelMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method
+func (m *MockScopedPanel) Clear(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Clear indicates an expected call of Clear
+func (mr *MockScopedPanelMockRecorder) Clear(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockScopedPanel)(nil).Clear), arg0, arg1)
+}
+
+// GetSettingIDs mocks base method
+func (m *MockScopedPanel) GetSettingIDs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettingIDs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetSettingIDs indicates an expected call of GetSettingIDs
+func (mr *MockScopedPanelMockRecorder) GetSettingIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettingIDs", reflect.TypeOf((*MockScopedPanel)(nil).GetSettingIDs))
+}
+
+// OpenDialog mocks base method
+func (m *MockScopedPanel) OpenDialog(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDialog", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// OpenDialog indicates an expected call of OpenDialog
+func (mr *MockScopedPanelMockRecorder) OpenDialog(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDialog", reflect.TypeOf((*MockScopedPanel)(nil).OpenDialog), arg0, arg1, arg2)
+}
+
+// Print mocks base method
+func (m *MockScopedPanel) Print(arg0, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Print", arg0, arg1)
+}
+
+// Print indicates an expected call of Print
+func (mr *MockScopedPanelMockRecorder) Print(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Print", reflect.TypeOf((*MockScopedPanel)(nil).Print), arg0, arg1)
+}
+
+// Reset mocks base method
+func (m *MockScopedPanel) Reset(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockScopedPanelMockRecorder) Reset(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockScopedPanel)(nil).Reset), arg0, arg1, arg2)
+}
+
+// Schema mocks base method
+func (m *MockScopedPanel) Schema() map[string]interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schema")
+	ret0, _ := ret[0].(map[string]interface{})
+	return ret0
+}
+
+// Schema indicates an expected call of Schema
+func (mr *MockScopedPanelMockRecorder) Schema() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schema", reflect.TypeOf((*MockScopedPanel)(nil).Schema))
+}
+
+// Set mocks base method
+func (m *MockScopedPanel) Set(arg0, arg1, arg2 string, arg3 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set
+func (mr *MockScopedPanelMockRecorder) Set(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockScopedPanel)(nil).Set), arg0, arg1, arg2, arg3)
+}
+
+// SubmitDialog mocks base method
+func (m *MockScopedPanel) SubmitDialog(arg0, arg1 string, arg2 map[string]interface{}) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitDialog", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitDialog indicates an expected call of SubmitDialog
+func (mr *MockScopedPanelMockRecorder) SubmitDialog(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitDialog", reflect.TypeOf((*MockScopedPanel)(nil).SubmitDialog), arg0, arg1, arg2)
+}
+
+// ToPost mocks base method
+func (m *MockScopedPanel) ToPost(arg0, arg1 string) (*model.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToPost", arg0, arg1)
+	ret0, _ := ret[0].(*model.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ToPost indicates an expected call of ToPost
+func (mr *MockScopedPanelMockRecorder) ToPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToPost", reflect.TypeOf((*MockScopedPanel)(nil).ToPost), arg0, arg1)
+}
+
+// URL mocks base method
+func (m *MockScopedPanel) URL() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "URL")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// URL indicates an expected call of URL
+func (mr *MockScopedPanelMockRecorder) URL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URL", reflect.TypeOf((*MockScopedPanel)(nil).URL))
+}
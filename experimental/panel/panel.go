@@ -1,7 +1,10 @@
 package panel
 
 import (
+	"crypto/sha256"
+	"encoding/base32"
 	"errors"
+	"strings"
 
 	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
 	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
@@ -18,6 +21,25 @@ type Panel interface {
 	Clear(userID string) error
 	URL() string
 	GetSettingIDs() []string
+	OpenDialog(userID, triggerID string) error
+	SubmitDialog(userID string, submission map[string]interface{}) (map[string]string, error)
+	Reset(userID, settingID string) error
+	Schema() map[string]interface{}
+}
+
+// Option defines each option that can be passed in the creation of the Panel.
+type Option func(*panel)
+
+// WithDialog adds a button to the panel opening an interactive dialog with every setting that
+// implements settings.DialogSetting, through the given opener, usually the pluginapi
+// FrontendService.
+func WithDialog(opener common.DialogOpener, title, buttonLabel, submitLabel string) Option {
+	return func(p *panel) {
+		p.dialogOpener = opener
+		p.dialogTitle = title
+		p.dialogButtonLabel = buttonLabel
+		p.dialogSubmitLabel = submitLabel
+	}
 }
 
 type panel struct {
@@ -28,6 +50,13 @@ type panel struct {
 	store          Store
 	settingHandler string
 	pluginURL      string
+
+	scope string
+
+	dialogOpener      common.DialogOpener
+	dialogTitle       string
+	dialogButtonLabel string
+	dialogSubmitLabel string
 }
 
 func NewSettingsPanel(
@@ -37,6 +66,7 @@ func NewSettingsPanel(
 	store Store,
 	settingHandler,
 	pluginURL string,
+	options ...Option,
 ) Panel {
 	settingsMap := make(map[string]settings.Setting)
 	settingKeys := []string{}
@@ -55,6 +85,10 @@ func NewSettingsPanel(
 		pluginURL:      pluginURL,
 	}
 
+	for _, option := range options {
+		option(panel)
+	}
+
 	for _, s := range settingsMap {
 		ftf := s.GetFreetextFetcher()
 		if ftf == nil {
@@ -68,16 +102,35 @@ func NewSettingsPanel(
 }
 
 func (p *panel) Set(userID, settingID string, value interface{}) error {
-	s, ok := p.settings[settingID]
-	if !ok {
-		return errors.New("cannot find setting " + settingID)
+	return p.set(userID, settingID, value)
+}
+
+// Reset sets the setting back to its default value. The setting must implement
+// settings.TypedSetting.
+func (p *panel) Reset(userID, settingID string) error {
+	return p.reset(userID, settingID)
+}
+
+// Schema returns a JSON schema describing the settings of the panel implementing
+// settings.SchemaSetting, keyed by setting ID, in the order they were given.
+func (p *panel) Schema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	order := []string{}
+	for _, key := range p.settingKeys {
+		s, ok := p.settings[key].(settings.SchemaSetting)
+		if !ok {
+			continue
+		}
+		properties[key] = s.GetSchema()
+		order = append(order, key)
 	}
 
-	err := s.Set(userID, value)
-	if err != nil {
-		return err
+	return map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": properties,
+		"x-order":    order,
 	}
-	return nil
 }
 
 func (p *panel) GetSettingIDs() []string {
@@ -89,53 +142,129 @@ func (p *panel) URL() string {
 }
 
 func (p *panel) Print(userID string) {
-	err := p.cleanPreviousSettingsPosts(userID)
+	p.print(userID, userID)
+}
+
+func (p *panel) ToPost(userID string) (*model.Post, error) {
+	return p.toPost(userID)
+}
+
+func (p *panel) Clear(userID string) error {
+	return p.cleanPreviousSettingsPosts(p.getPostKey(userID, userID))
+}
+
+func (p *panel) OpenDialog(userID, triggerID string) error {
+	return p.openDialog(userID, triggerID)
+}
+
+func (p *panel) SubmitDialog(userID string, submission map[string]interface{}) (map[string]string, error) {
+	return p.submitDialog(userID, userID, submission)
+}
+
+// The methods below take the ID of the owner of the settings: the user for personal panels, and
+// the channel or team for scoped panels.
+
+func (p *panel) set(ownerID, settingID string, value interface{}) error {
+	s, ok := p.settings[settingID]
+	if !ok {
+		return errors.New("cannot find setting " + settingID)
+	}
+
+	err := s.Set(ownerID, value)
 	if err != nil {
-		p.logger.Errorf("could not clean previous setting post, " + err.Error())
+		return err
 	}
+	return nil
+}
 
-	sas := []*model.SlackAttachment{}
-	for _, key := range p.settingKeys {
-		s := p.settings[key]
-		sa, loopErr := s.GetSlackAttachments(userID, p.pluginURL+p.settingHandler, p.isSettingDisabled(userID, s))
-		if loopErr != nil {
-			p.logger.Errorf("error creating the slack attachment, err=" + loopErr.Error())
-			continue
-		}
-		sas = append(sas, sa)
+func (p *panel) reset(ownerID, settingID string) error {
+	s, ok := p.settings[settingID]
+	if !ok {
+		return errors.New("cannot find setting " + settingID)
 	}
-	postID, err := p.poster.DMWithAttachments(userID, sas...)
+
+	typedSetting, ok := s.(settings.TypedSetting)
+	if !ok {
+		return errors.New("setting " + settingID + " cannot be reset")
+	}
+
+	return typedSetting.Reset(ownerID)
+}
+
+func (p *panel) print(userID, ownerID string) {
+	postKey := p.getPostKey(userID, ownerID)
+	err := p.cleanPreviousSettingsPosts(postKey)
+	if err != nil {
+		p.logger.Errorf("could not clean previous setting post, " + err.Error())
+	}
+
+	postID, err := p.poster.DMWithAttachments(userID, p.getSlackAttachments(ownerID)...)
 	if err != nil {
 		p.logger.Errorf("error creating the message, err=", err.Error())
 		return
 	}
 
-	err = p.store.SetPanelPostID(userID, postID)
+	err = p.store.SetPanelPostID(postKey, postID)
 	if err != nil {
 		p.logger.Errorf("could not set the post IDs, err=", err.Error())
 	}
 }
 
-func (p *panel) ToPost(userID string) (*model.Post, error) {
+func (p *panel) toPost(ownerID string) (*model.Post, error) {
 	post := &model.Post{}
+	model.ParseSlackAttachment(post, p.getSlackAttachments(ownerID))
+	return post, nil
+}
 
+func (p *panel) getSlackAttachments(ownerID string) []*model.SlackAttachment {
 	sas := []*model.SlackAttachment{}
 	for _, key := range p.settingKeys {
 		s := p.settings[key]
-		sa, err := s.GetSlackAttachments(userID, p.pluginURL+p.settingHandler, p.isSettingDisabled(userID, s))
+		sa, err := s.GetSlackAttachments(ownerID, p.pluginURL+p.settingHandler, p.isSettingDisabled(ownerID, s))
 		if err != nil {
 			p.logger.Errorf("error creating the slack attachment for setting %s, err=%s", s.GetID(), err.Error())
 			continue
 		}
 		sas = append(sas, sa)
 	}
+	if sa := p.dialogAttachment(); sa != nil {
+		sas = append(sas, sa)
+	}
 
-	model.ParseSlackAttachment(post, sas)
-	return post, nil
+	if p.scope != "" {
+		for _, sa := range sas {
+			for _, action := range sa.Actions {
+				if action.Integration == nil {
+					continue
+				}
+				if action.Integration.Context == nil {
+					action.Integration.Context = map[string]interface{}{}
+				}
+				action.Integration.Context[settings.ContextScopeIDKey] = ownerID
+			}
+		}
+	}
+
+	return sas
+}
+
+// postKeyLength is the length of the post keys of scoped panels, the length of a user ID.
+const postKeyLength = 26
+
+// getPostKey returns the key under which the ID of the panel post of the user is stored. Scoped
+// panels hash the user and scope IDs into a key as long as a user ID, so that it fits in the KV
+// store wherever a user ID does.
+func (p *panel) getPostKey(userID, ownerID string) string {
+	if p.scope == "" {
+		return userID
+	}
+
+	hash := sha256.Sum256([]byte(userID + "-" + ownerID))
+	return strings.ToLower(base32.StdEncoding.EncodeToString(hash[:]))[:postKeyLength]
 }
 
-func (p *panel) cleanPreviousSettingsPosts(userID string) error {
-	postID, err := p.store.GetPanelPostID(userID)
+func (p *panel) cleanPreviousSettingsPosts(postKey string) error {
+	postID, err := p.store.GetPanelPostID(postKey)
 	if err == common.ErrNotFound {
 		return nil
 	}
@@ -144,12 +273,17 @@ func (p *panel) cleanPreviousSettingsPosts(userID string) error {
 		return err
 	}
 
+	// The KV store returns no error for missing keys.
+	if postID == "" {
+		return nil
+	}
+
 	err = p.poster.DeletePost(postID)
 	if err != nil {
 		p.logger.Errorf("could not delete setting post, %s", err)
 	}
 
-	err = p.store.DeletePanelPostID(userID)
+	err = p.store.DeletePanelPostID(postKey)
 	if err != nil {
 		return err
 	}
@@ -157,11 +291,7 @@ func (p *panel) cleanPreviousSettingsPosts(userID string) error {
 	return nil
 }
 
-func (p *panel) Clear(userID string) error {
-	return p.cleanPreviousSettingsPosts(userID)
-}
-
-func (p *panel) isSettingDisabled(userID string, s settings.Setting) bool {
+func (p *panel) isSettingDisabled(ownerID string, s settings.Setting) bool {
 	dependencyID := s.GetDependency()
 	if dependencyID == "" {
 		return false
@@ -172,7 +302,7 @@ func (p *panel) isSettingDisabled(userID string, s settings.Setting) bool {
 		return false
 	}
 
-	value, err := dependency.Get(userID)
+	value, err := dependency.Get(ownerID)
 	if err != nil {
 		p.logger.Errorf("cannot get dependency %s value", dependencyID)
 		return false
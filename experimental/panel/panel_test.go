@@ -0,0 +1,201 @@
+package panel
+
+import (
+	"net/http"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
+	"github.com/mattermost/mattermost-plugin-api/experimental/panel/settings"
+)
+
+type memoryStore map[string]interface{}
+
+func (s memoryStore) SetSetting(userID, settingID string, value interface{}) error {
+	s[userID+settingID] = value
+	return nil
+}
+
+func (s memoryStore) GetSetting(userID, settingID string) (interface{}, error) {
+	return s[userID+settingID], nil
+}
+
+func TestSchema(t *testing.T) {
+	store := memoryStore{}
+	p := NewSettingsPanel([]settings.Setting{
+		settings.NewEmptySetting("header", "Header", "Not a setting"),
+		settings.NewBoolSetting("enabled", "Enabled", "", "", store, settings.WithDefault(true)),
+		settings.NewOptionSetting("color", "Color", "", "enabled", []string{"red", "blue"}, store),
+	}, nil, logger.NewNilLogger(), nil, "/settings", "/plugins/test")
+
+	schema := p.Schema()
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, []string{"enabled", "color"}, schema["x-order"])
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type":        "boolean",
+		"title":       "Enabled",
+		"description": "",
+		"default":     true,
+	}, properties["enabled"])
+	assert.Equal(t, map[string]interface{}{
+		"type":         "string",
+		"title":        "Color",
+		"description":  "",
+		"enum":         []string{"red", "blue"},
+		"x-depends-on": "enabled",
+	}, properties["color"])
+}
+
+func TestReset(t *testing.T) {
+	store := memoryStore{}
+	p := NewSettingsPanel([]settings.Setting{
+		settings.NewBoolSetting("enabled", "Enabled", "", "", store, settings.WithDefault(true)),
+		settings.NewEmptySetting("header", "Header", "Not a setting"),
+	}, nil, logger.NewNilLogger(), nil, "/settings", "/plugins/test")
+
+	assert.NoError(t, p.Set("user-id", "enabled", settings.FalseString))
+	assert.Equal(t, false, store["user-idenabled"])
+
+	assert.NoError(t, p.Reset("user-id", "enabled"))
+	assert.Nil(t, store["user-idenabled"])
+	assert.Error(t, p.Reset("user-id", "header"))
+	assert.Error(t, p.Reset("user-id", "unknown"))
+}
+
+type fakeChecker struct {
+	allowed map[string]bool
+}
+
+func (c fakeChecker) HasPermissionToChannel(userID, channelID string, permission *model.Permission) bool {
+	return c.allowed[userID+channelID+permission.Id]
+}
+
+func (c fakeChecker) HasPermissionToTeam(userID, teamID string, permission *model.Permission) bool {
+	return c.allowed[userID+teamID+permission.Id]
+}
+
+func TestScopedPanel(t *testing.T) {
+	store := memoryStore{}
+	checker := fakeChecker{allowed: map[string]bool{
+		"admin" + "channel-id" + model.PERMISSION_MANAGE_PUBLIC_CHANNEL_PROPERTIES.Id: true,
+		"admin" + "channel-id" + model.PERMISSION_READ_CHANNEL.Id:                     true,
+		"user" + "channel-id" + model.PERMISSION_READ_CHANNEL.Id:                      true,
+	}}
+	settingList := []settings.Setting{
+		settings.NewBoolSetting("notify", "Notify", "", "", store),
+	}
+
+	p, err := NewChannelSettingsPanel(settingList, nil, logger.NewNilLogger(), nil, "/settings", "/plugins/test", checker, model.PERMISSION_MANAGE_PUBLIC_CHANNEL_PROPERTIES)
+	require.NoError(t, err)
+
+	require.Equal(t, ErrPermissionDenied, p.Set("user", "channel-id", "notify", settings.TrueString))
+	require.Equal(t, ErrPermissionDenied, p.Reset("user", "channel-id", "notify"))
+	require.Error(t, p.Set("admin", "", "notify", settings.TrueString))
+	assert.Empty(t, store)
+
+	require.NoError(t, p.Set("admin", "channel-id", "notify", settings.TrueString))
+	assert.Equal(t, true, store["channel-idnotify"])
+
+	post, err := p.ToPost("user", "channel-id")
+	require.NoError(t, err)
+	for _, action := range post.Attachments()[0].Actions {
+		assert.Equal(t, "channel-id", action.Integration.Context[settings.ContextScopeIDKey])
+	}
+
+	_, err = p.ToPost("stranger", "channel-id")
+	require.Equal(t, ErrViewDenied, err)
+	require.Equal(t, ErrViewDenied, p.Clear("stranger", "channel-id"))
+	require.Error(t, p.Clear("user", ""))
+
+	_, err = NewTeamSettingsPanel(settingList, nil, logger.NewNilLogger(), nil, "/settings", "/plugins/test", nil, model.PERMISSION_MANAGE_TEAM)
+	require.Error(t, err)
+}
+
+type fakePoster struct {
+	poster.Poster
+	posted  []string
+	deleted []string
+}
+
+func (p *fakePoster) DMWithAttachments(userID string, attachments ...*model.SlackAttachment) (string, error) {
+	postID := model.NewId()
+	p.posted = append(p.posted, postID)
+	return postID, nil
+}
+
+func (p *fakePoster) DeletePost(postID string) error {
+	p.deleted = append(p.deleted, postID)
+	return nil
+}
+
+// newPostStore returns a panel store backed by an in-memory KV store, which rejects keys longer
+// than the limit of the server.
+func newPostStore() Store {
+	kv := map[string][]byte{}
+	checkKey := func(key string) *model.AppError {
+		if utf8.RuneCountInString(key) > model.KEY_VALUE_KEY_MAX_RUNES {
+			return model.NewAppError("KVSetWithOptions", "key too long", nil, key, http.StatusBadRequest)
+		}
+		return nil
+	}
+
+	api := &plugintest.API{}
+	api.On("KVGet", mock.AnythingOfType("string")).Return(
+		func(key string) []byte { return kv[key] },
+		checkKey,
+	)
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(
+		func(key string, value []byte, options model.PluginKVSetOptions) bool {
+			if checkKey(key) != nil {
+				return false
+			}
+			if value == nil {
+				delete(kv, key)
+			} else {
+				kv[key] = value
+			}
+			return true
+		},
+		func(key string, value []byte, options model.PluginKVSetOptions) *model.AppError {
+			return checkKey(key)
+		},
+	)
+
+	return NewPanelStore(*pluginapi.NewClient(api, &plugintest.Driver{}), "settings_panel")
+}
+
+func TestScopedPanelPost(t *testing.T) {
+	userID := model.NewId()
+	channelID := model.NewId()
+	checker := fakeChecker{allowed: map[string]bool{userID + channelID + model.PERMISSION_READ_CHANNEL.Id: true}}
+	p := &fakePoster{}
+	store := newPostStore()
+
+	panel, err := NewChannelSettingsPanel(nil, p, logger.NewNilLogger(), store, "/settings", "/plugins/test", checker, model.PERMISSION_MANAGE_PUBLIC_CHANNEL_PROPERTIES)
+	require.NoError(t, err)
+
+	panel.Print(userID, channelID)
+	panel.Print(userID, channelID)
+	require.Len(t, p.posted, 2)
+	assert.Equal(t, p.posted[:1], p.deleted)
+
+	require.NoError(t, panel.Clear(userID, channelID))
+	assert.Equal(t, p.posted, p.deleted)
+
+	// The panel of the channel is independent of the panel of the user.
+	personal := NewSettingsPanel(nil, p, logger.NewNilLogger(), store, "/settings", "/plugins/test")
+	personal.Print(userID)
+	panel.Print(userID, channelID)
+	require.NoError(t, personal.Clear(userID))
+	assert.Equal(t, p.posted[:3], p.deleted)
+}
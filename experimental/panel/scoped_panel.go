@@ -0,0 +1,265 @@
+package panel
+
+import (
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/poster"
+	"github.com/mattermost/mattermost-plugin-api/experimental/panel/settings"
+)
+
+const (
+	// ScopeChannel is the scope of panels holding channel settings.
+	ScopeChannel = "channel"
+	// ScopeTeam is the scope of panels holding team settings.
+	ScopeTeam = "team"
+)
+
+// ErrPermissionDenied is returned when the user does not have the permission to change the
+// settings of a scoped panel.
+var ErrPermissionDenied = errors.New("you do not have permission to change these settings")
+
+// ErrViewDenied is returned when the user cannot see the settings of a scoped panel, because the
+// user is not a member of the channel or the team.
+var ErrViewDenied = errors.New("you do not have permission to view these settings")
+
+// PermissionChecker checks the permissions of users on channels and teams. It is implemented by
+// the pluginapi UserService.
+type PermissionChecker interface {
+	HasPermissionToChannel(userID, channelID string, permission *model.Permission) bool
+	HasPermissionToTeam(userID, teamID string, permission *model.Permission) bool
+}
+
+// ScopedPanel is a panel whose settings belong to a channel or a team, identified by scopeID,
+// instead of to the user. The panel is shown to the user in a DM. The user must be a member of the
+// channel or the team to view or clear the panel, and must have the permission given at creation
+// to change any value.
+type ScopedPanel interface {
+	Set(userID, scopeID, settingID string, value interface{}) error
+	Print(userID, scopeID string)
+	ToPost(userID, scopeID string) (*model.Post, error)
+	Clear(userID, scopeID string) error
+	URL() string
+	GetSettingIDs() []string
+	OpenDialog(userID, scopeID, triggerID string) error
+	SubmitDialog(userID, scopeID string, submission map[string]interface{}) (map[string]string, error)
+	Reset(userID, scopeID, settingID string) error
+	Schema() map[string]interface{}
+}
+
+type scopedPanel struct {
+	*panel
+	checker    PermissionChecker
+	permission *model.Permission
+}
+
+// NewChannelSettingsPanel creates a panel for the settings of channels. Values can only be
+// changed by users with the given permission on the channel, e.g.
+// model.PERMISSION_MANAGE_PUBLIC_CHANNEL_PROPERTIES.
+//
+// Freetext settings are not supported, since their values are not fetched in the channel; use
+// WithDialog to edit text values instead.
+func NewChannelSettingsPanel(
+	settingList []settings.Setting,
+	p poster.Poster,
+	l logger.Logger,
+	store Store,
+	settingHandler,
+	pluginURL string,
+	checker PermissionChecker,
+	permission *model.Permission,
+	options ...Option,
+) (ScopedPanel, error) {
+	return newScopedPanel(ScopeChannel, settingList, p, l, store, settingHandler, pluginURL, checker, permission, options)
+}
+
+// NewTeamSettingsPanel creates a panel for the settings of teams. Values can only be changed by
+// users with the given permission on the team, e.g. model.PERMISSION_MANAGE_TEAM.
+//
+// Freetext settings are not supported, since their values are not fetched in the team; use
+// WithDialog to edit text values instead.
+func NewTeamSettingsPanel(
+	settingList []settings.Setting,
+	p poster.Poster,
+	l logger.Logger,
+	store Store,
+	settingHandler,
+	pluginURL string,
+	checker PermissionChecker,
+	permission *model.Permission,
+	options ...Option,
+) (ScopedPanel, error) {
+	return newScopedPanel(ScopeTeam, settingList, p, l, store, settingHandler, pluginURL, checker, permission, options)
+}
+
+func newScopedPanel(
+	scope string,
+	settingList []settings.Setting,
+	p poster.Poster,
+	l logger.Logger,
+	store Store,
+	settingHandler,
+	pluginURL string,
+	checker PermissionChecker,
+	permission *model.Permission,
+	options []Option,
+) (ScopedPanel, error) {
+	if checker == nil || permission == nil {
+		return nil, errors.New("scoped panels need a permission checker and a permission")
+	}
+
+	for _, s := range settingList {
+		if s.GetFreetextFetcher() != nil {
+			return nil, errors.Errorf("setting %s: freetext settings are not supported in %s panels", s.GetID(), scope)
+		}
+	}
+
+	options = append(options, func(p *panel) {
+		p.scope = scope
+	})
+
+	return &scopedPanel{
+		panel:      NewSettingsPanel(settingList, p, l, store, settingHandler, pluginURL, options...).(*panel),
+		checker:    checker,
+		permission: permission,
+	}, nil
+}
+
+// checkPermission checks that the user can change the settings.
+func (sp *scopedPanel) checkPermission(userID, scopeID string) error {
+	return sp.check(userID, scopeID, sp.permission, ErrPermissionDenied)
+}
+
+// checkViewPermission checks that the user can see the settings, i.e. is a member of the channel
+// or the team.
+func (sp *scopedPanel) checkViewPermission(userID, scopeID string) error {
+	permission := model.PERMISSION_READ_CHANNEL
+	if sp.scope == ScopeTeam {
+		permission = model.PERMISSION_VIEW_TEAM
+	}
+
+	return sp.check(userID, scopeID, permission, ErrViewDenied)
+}
+
+func (sp *scopedPanel) check(userID, scopeID string, permission *model.Permission, denied error) error {
+	if scopeID == "" {
+		return errors.Errorf("missing %s id", sp.scope)
+	}
+
+	var allowed bool
+	switch sp.scope {
+	case ScopeChannel:
+		allowed = sp.checker.HasPermissionToChannel(userID, scopeID, permission)
+	case ScopeTeam:
+		allowed = sp.checker.HasPermissionToTeam(userID, scopeID, permission)
+	}
+
+	if !allowed {
+		return denied
+	}
+
+	return nil
+}
+
+func (sp *scopedPanel) Set(userID, scopeID, settingID string, value interface{}) error {
+	err := sp.checkPermission(userID, scopeID)
+	if err != nil {
+		return err
+	}
+
+	return sp.set(scopeID, settingID, value)
+}
+
+func (sp *scopedPanel) Reset(userID, scopeID, settingID string) error {
+	err := sp.checkPermission(userID, scopeID)
+	if err != nil {
+		return err
+	}
+
+	return sp.reset(scopeID, settingID)
+}
+
+func (sp *scopedPanel) Print(userID, scopeID string) {
+	err := sp.checkViewPermission(userID, scopeID)
+	if err != nil {
+		sp.logger.Debugf("user %s cannot view the settings of %s %s: %s", userID, sp.scope, scopeID, err.Error())
+		_, err = sp.poster.DM(userID, "Cannot show the settings: %s.", err.Error())
+		if err != nil {
+			sp.logger.Errorf("could not send the error message, err=%s", err.Error())
+		}
+		return
+	}
+
+	sp.print(userID, scopeID)
+}
+
+func (sp *scopedPanel) ToPost(userID, scopeID string) (*model.Post, error) {
+	err := sp.checkViewPermission(userID, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sp.toPost(scopeID)
+}
+
+func (sp *scopedPanel) Clear(userID, scopeID string) error {
+	err := sp.checkViewPermission(userID, scopeID)
+	if err != nil {
+		return err
+	}
+
+	return sp.cleanPreviousSettingsPosts(sp.getPostKey(userID, scopeID))
+}
+
+func (sp *scopedPanel) OpenDialog(userID, scopeID, triggerID string) error {
+	err := sp.checkPermission(userID, scopeID)
+	if err != nil {
+		return err
+	}
+
+	return sp.openDialog(scopeID, triggerID)
+}
+
+func (sp *scopedPanel) SubmitDialog(userID, scopeID string, submission map[string]interface{}) (map[string]string, error) {
+	err := sp.checkPermission(userID, scopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sp.submitDialog(userID, scopeID, submission)
+}
+
+// userPanel exposes a Panel as a ScopedPanel, ignoring the scope, so the same handler serves
+// both kinds of panels.
+type userPanel struct {
+	Panel
+}
+
+func (up userPanel) Set(userID, _, settingID string, value interface{}) error {
+	return up.Panel.Set(userID, settingID, value)
+}
+
+func (up userPanel) Print(userID, _ string) {
+	up.Panel.Print(userID)
+}
+
+func (up userPanel) ToPost(userID, _ string) (*model.Post, error) {
+	return up.Panel.ToPost(userID)
+}
+
+func (up userPanel) Clear(userID, _ string) error {
+	return up.Panel.Clear(userID)
+}
+
+func (up userPanel) OpenDialog(userID, _, triggerID string) error {
+	return up.Panel.OpenDialog(userID, triggerID)
+}
+
+func (up userPanel) SubmitDialog(userID, _ string, submission map[string]interface{}) (map[string]string, error) {
+	return up.Panel.SubmitDialog(userID, submission)
+}
+
+func (up userPanel) Reset(userID, _, settingID string) error {
+	return up.Panel.Reset(userID, settingID)
+}
@@ -4,11 +4,43 @@ import (
 	"github.com/mattermost/mattermost-plugin-api/experimental/freetextfetcher"
 )
 
+// SettingOption defines each option that can be passed in the creation of a Setting
+type SettingOption func(*baseSetting)
+
+// WithDefault sets the value returned by the setting when no value has been stored, or after a reset
+func WithDefault(value interface{}) SettingOption {
+	return func(s *baseSetting) {
+		s.defaultValue = value
+	}
+}
+
+// WithValidator adds a validator called with the parsed value before it is stored
+func WithValidator(validate func(value interface{}) error) SettingOption {
+	return func(s *baseSetting) {
+		s.validate = validate
+	}
+}
+
 type baseSetting struct {
-	title       string
-	description string
-	id          string
-	dependsOn   string
+	title        string
+	description  string
+	id           string
+	dependsOn    string
+	defaultValue interface{}
+	validate     func(value interface{}) error
+}
+
+func newBaseSetting(id, title, description, dependsOn string, options []SettingOption) baseSetting {
+	s := baseSetting{
+		title:       title,
+		description: description,
+		id:          id,
+		dependsOn:   dependsOn,
+	}
+	for _, option := range options {
+		option(&s)
+	}
+	return s
 }
 
 func (s *baseSetting) GetID() string {
@@ -34,3 +66,36 @@ func (s *baseSetting) IsDisabled(foreignValue interface{}) bool {
 func (s *baseSetting) GetFreetextFetcher() freetextfetcher.FreetextFetcher {
 	return nil
 }
+
+func (s *baseSetting) GetDefault() interface{} {
+	return s.defaultValue
+}
+
+func (s *baseSetting) runValidator(value interface{}) error {
+	if s.validate == nil {
+		return nil
+	}
+	return s.validate(value)
+}
+
+func (s *baseSetting) reset(store SettingStore, userID string) error {
+	if deleter, ok := store.(SettingDeleter); ok {
+		return deleter.DeleteSetting(userID, s.id)
+	}
+	return store.SetSetting(userID, s.id, nil)
+}
+
+func (s *baseSetting) baseSchema(schemaType string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":        schemaType,
+		"title":       s.title,
+		"description": s.description,
+	}
+	if s.defaultValue != nil {
+		schema["default"] = s.defaultValue
+	}
+	if s.dependsOn != "" {
+		schema["x-depends-on"] = s.dependsOn
+	}
+	return schema
+}
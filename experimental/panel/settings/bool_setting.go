@@ -13,25 +13,29 @@ type boolSetting struct {
 }
 
 // NewBoolSetting creates a new setting input for boolean values
-func NewBoolSetting(id, title, description, dependsOn string, store SettingStore) Setting {
+func NewBoolSetting(id, title, description, dependsOn string, store SettingStore, options ...SettingOption) Setting {
 	return &boolSetting{
-		baseSetting: baseSetting{
-			title:       title,
-			description: description,
-			id:          id,
-			dependsOn:   dependsOn,
-		},
-		store: store,
+		baseSetting: newBaseSetting(id, title, description, dependsOn, options),
+		store:       store,
 	}
 }
 
 func (s *boolSetting) Set(userID string, value interface{}) error {
+	if value == nil || value == "" {
+		return s.Reset(userID)
+	}
+
 	boolValue := false
-	if value == TrueString {
+	if value == TrueString || value == true {
 		boolValue = true
 	}
 
-	err := s.store.SetSetting(userID, s.id, boolValue)
+	err := s.runValidator(boolValue)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.SetSetting(userID, s.id, boolValue)
 	if err != nil {
 		return err
 	}
@@ -44,6 +48,12 @@ func (s *boolSetting) Get(userID string) (interface{}, error) {
 	if err != nil {
 		return "", err
 	}
+	if value == nil {
+		value = s.defaultValue
+	}
+	if value == nil {
+		value = false
+	}
 	boolValue, ok := value.(bool)
 	if !ok {
 		return "", errors.New("current value is not a bool")
@@ -109,6 +119,34 @@ func (s *boolSetting) GetSlackAttachments(userID, settingHandler string, disable
 	return &sa, nil
 }
 
+func (s *boolSetting) GetDialogElement(userID string) (*model.DialogElement, error) {
+	currentValue, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DialogElement{
+		DisplayName: s.title,
+		Name:        s.id,
+		Type:        "bool",
+		Default:     currentValue.(string),
+		HelpText:    s.description,
+		Optional:    true,
+	}, nil
+}
+
+func (s *boolSetting) ValidateDialogValue(value interface{}) string {
+	return ""
+}
+
+func (s *boolSetting) Reset(userID string) error {
+	return s.reset(s.store, userID)
+}
+
+func (s *boolSetting) GetSchema() map[string]interface{} {
+	return s.baseSchema("boolean")
+}
+
 func (s *boolSetting) IsDisabled(foreignValue interface{}) bool {
 	return foreignValue == FalseString
 }
@@ -18,6 +18,7 @@ type freetextSetting struct {
 	pluginURL     string
 	store         SettingStore
 	ftf           freetextfetcher.FreetextFetcher
+	validate      func(string) string
 }
 
 // FreetextInfo defines the information needed in the payload for freetext settings
@@ -26,7 +27,8 @@ type FreetextInfo struct {
 	UserID    string
 }
 
-// NewFreetextSetting creates a new setting input to add any text
+// NewFreetextSetting creates a new setting input to add any text. baseURL must be unique among the
+// fetchers of ftfManager.
 func NewFreetextSetting(
 	id,
 	title,
@@ -36,28 +38,38 @@ func NewFreetextSetting(
 	store SettingStore,
 	baseURL,
 	pluginURL string,
-	ftfStore freetextfetcher.FreetextStore,
+	ftfManager freetextfetcher.Manager,
 	validate func(string) string,
 	r *mux.Router,
 	p poster.Poster,
-) Setting {
-	setting := &freetextSetting{
-		baseSetting: baseSetting{
-			title:       title,
-			description: description,
-			id:          id,
-			dependsOn:   dependsOn,
-		},
+	options ...SettingOption,
+) (Setting, error) {
+	ftf, err := freetextfetcher.NewFreetextFetcher(baseURL, ftfManager, validate, nil, nil, r, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &freetextSetting{
+		baseSetting:   newBaseSetting(id, title, description, dependsOn, options),
 		modifyMessage: modifyMessage,
 		store:         store,
 		pluginURL:     pluginURL,
-	}
-	setting.ftf = freetextfetcher.NewFreetextFetcher(baseURL, ftfStore, validate, nil, nil, r, p)
-	return setting
+		validate:      validate,
+		ftf:           ftf,
+	}, nil
 }
 
 func (s *freetextSetting) Set(userID string, value interface{}) error {
-	err := s.store.SetSetting(userID, s.id, value)
+	if value == nil || value == "" {
+		return s.Reset(userID)
+	}
+
+	err := s.runValidator(value)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.SetSetting(userID, s.id, value)
 	if err != nil {
 		return err
 	}
@@ -70,6 +82,12 @@ func (s *freetextSetting) Get(userID string) (interface{}, error) {
 	if err != nil {
 		return "", err
 	}
+	if value == nil {
+		value = s.defaultValue
+	}
+	if value == nil {
+		value = ""
+	}
 	stringValue, ok := value.(string)
 	if !ok {
 		return "", errors.New("current value is not a string")
@@ -123,6 +141,39 @@ func (s *freetextSetting) GetSlackAttachments(userID, settingHandler string, dis
 	return &sa, nil
 }
 
+func (s *freetextSetting) GetDialogElement(userID string) (*model.DialogElement, error) {
+	currentValue, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DialogElement{
+		DisplayName: s.title,
+		Name:        s.id,
+		Type:        "text",
+		Default:     currentValue.(string),
+		HelpText:    s.description,
+		Optional:    true,
+	}, nil
+}
+
+func (s *freetextSetting) ValidateDialogValue(value interface{}) string {
+	stringValue, _ := value.(string)
+	if s.validate == nil || stringValue == "" {
+		return ""
+	}
+
+	return s.validate(stringValue)
+}
+
+func (s *freetextSetting) Reset(userID string) error {
+	return s.reset(s.store, userID)
+}
+
+func (s *freetextSetting) GetSchema() map[string]interface{} {
+	return s.baseSchema("string")
+}
+
 func (s *freetextSetting) IsDisabled(foreignValue interface{}) bool {
 	return foreignValue == FalseString
 }
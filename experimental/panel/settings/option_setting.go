@@ -9,26 +9,43 @@ import (
 
 type optionSetting struct {
 	baseSetting
-	options []string
-	store   SettingStore
+	options    []string
+	store      SettingStore
+	dialogType string
 }
 
 // NewOptionSetting creates a new setting input to select from a dropdown
-func NewOptionSetting(id, title, description, dependsOn string, options []string, store SettingStore) Setting {
+func NewOptionSetting(id, title, description, dependsOn string, options []string, store SettingStore, settingOptions ...SettingOption) Setting {
 	return &optionSetting{
-		baseSetting: baseSetting{
-			title:       title,
-			description: description,
-			id:          id,
-			dependsOn:   dependsOn,
-		},
-		options: options,
-		store:   store,
+		baseSetting: newBaseSetting(id, title, description, dependsOn, settingOptions),
+		options:     options,
+		store:       store,
+		dialogType:  "select",
+	}
+}
+
+// NewRadioSetting creates a new setting input to select from a dropdown, shown as radio buttons
+// in dialogs
+func NewRadioSetting(id, title, description, dependsOn string, options []string, store SettingStore, settingOptions ...SettingOption) Setting {
+	return &optionSetting{
+		baseSetting: newBaseSetting(id, title, description, dependsOn, settingOptions),
+		options:     options,
+		store:       store,
+		dialogType:  "radio",
 	}
 }
 
 func (s *optionSetting) Set(userID string, value interface{}) error {
-	err := s.store.SetSetting(userID, s.id, value)
+	if value == nil || value == "" {
+		return s.Reset(userID)
+	}
+
+	err := s.runValidator(value)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.SetSetting(userID, s.id, value)
 	if err != nil {
 		return err
 	}
@@ -41,6 +58,12 @@ func (s *optionSetting) Get(userID string) (interface{}, error) {
 	if err != nil {
 		return "", err
 	}
+	if value == nil {
+		value = s.defaultValue
+	}
+	if value == nil {
+		value = ""
+	}
 	valueString, ok := value.(string)
 	if !ok {
 		return "", errors.New("current value is not a string")
@@ -86,6 +109,36 @@ func (s *optionSetting) GetSlackAttachments(userID, settingHandler string, disab
 	return &sa, nil
 }
 
+func (s *optionSetting) GetDialogElement(userID string) (*model.DialogElement, error) {
+	currentValue, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DialogElement{
+		DisplayName: s.title,
+		Name:        s.id,
+		Type:        s.dialogType,
+		Default:     currentValue.(string),
+		HelpText:    s.description,
+		Options:     stringsToOptions(s.options),
+	}, nil
+}
+
+func (s *optionSetting) ValidateDialogValue(value interface{}) string {
+	return ""
+}
+
+func (s *optionSetting) Reset(userID string) error {
+	return s.reset(s.store, userID)
+}
+
+func (s *optionSetting) GetSchema() map[string]interface{} {
+	schema := s.baseSchema("string")
+	schema["enum"] = s.options
+	return schema
+}
+
 func (s *optionSetting) IsDisabled(foreignValue interface{}) bool {
 	return foreignValue == FalseString
 }
@@ -13,15 +13,10 @@ type readOnlySetting struct {
 }
 
 // NewReadOnlySetting creates a new panel value that only read from the setting
-func NewReadOnlySetting(id, title, description, dependsOn string, store SettingStore) Setting {
+func NewReadOnlySetting(id, title, description, dependsOn string, store SettingStore, options ...SettingOption) Setting {
 	return &readOnlySetting{
-		baseSetting: baseSetting{
-			title:       title,
-			description: description,
-			id:          id,
-			dependsOn:   dependsOn,
-		},
-		store: store,
+		baseSetting: newBaseSetting(id, title, description, dependsOn, options),
+		store:       store,
 	}
 }
 
@@ -30,6 +25,12 @@ func (s *readOnlySetting) Get(userID string) (interface{}, error) {
 	if err != nil {
 		return "", err
 	}
+	if value == nil {
+		value = s.defaultValue
+	}
+	if value == nil {
+		value = ""
+	}
 	stringValue, ok := value.(string)
 	if !ok {
 		return "", errors.New("current value is not a string")
@@ -64,6 +65,12 @@ func (s *readOnlySetting) GetSlackAttachments(userID, settingHandler string, dis
 	return &sa, nil
 }
 
+func (s *readOnlySetting) GetSchema() map[string]interface{} {
+	schema := s.baseSchema("string")
+	schema["readOnly"] = true
+	return schema
+}
+
 func (s *readOnlySetting) IsDisabled(foreignValue interface{}) bool {
 	return foreignValue == FalseString
 }
@@ -13,6 +13,12 @@ const (
 	ContextButtonValueKey = "button_value"
 	// ContextOptionValueKey defines the key used in the context to store a selected option value
 	ContextOptionValueKey = "selected_option"
+	// ContextDialogKey defines the key used in the context to open the settings dialog
+	ContextDialogKey = "dialog"
+	// ContextResetKey defines the key used in the context to reset a setting to its default value
+	ContextResetKey = "reset"
+	// ContextScopeIDKey defines the key used in the context to store the channel or team ID of scoped panels
+	ContextScopeIDKey = "scope_id"
 
 	// DisabledString defines the string used to show that a setting is disabled
 	DisabledString = "Disabled"
@@ -34,3 +40,25 @@ type Setting interface {
 	GetSlackAttachments(userID, settingHandler string, disabled bool) (*model.SlackAttachment, error)
 	GetFreetextFetcher() freetextfetcher.FreetextFetcher
 }
+
+// DialogSetting defines the behavior of settings that can be edited through an interactive dialog
+type DialogSetting interface {
+	Setting
+	GetDialogElement(userID string) (*model.DialogElement, error)
+	// ValidateDialogValue returns an error message if the submitted value is not valid
+	ValidateDialogValue(value interface{}) string
+}
+
+// TypedSetting defines the behavior of settings with a default value and a validator, that can be
+// reset to their default value
+type TypedSetting interface {
+	Setting
+	GetDefault() interface{}
+	Reset(userID string) error
+}
+
+// SchemaSetting defines the behavior of settings that can describe themselves as a JSON schema
+type SchemaSetting interface {
+	Setting
+	GetSchema() map[string]interface{}
+}
@@ -5,3 +5,9 @@ type SettingStore interface {
 	SetSetting(userID, settingID string, value interface{}) error
 	GetSetting(userID, settingID string) (interface{}, error)
 }
+
+// SettingDeleter defines the behavior of setting stores able to delete a setting. Settings stored
+// in other stores are reset by setting a nil value.
+type SettingDeleter interface {
+	DeleteSetting(userID, settingID string) error
+}
@@ -0,0 +1,470 @@
+package settings
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// NotSetString defines the string used to show that a setting has no value
+const NotSetString = "Not set"
+
+const (
+	dataSourceUsers    = "users"
+	dataSourceChannels = "channels"
+)
+
+// valueKind describes how the values of a typed setting are parsed, stored and shown.
+type valueKind struct {
+	// parse converts a value coming from the store, an action or a dialog into the typed value.
+	parse func(value interface{}) (interface{}, error)
+	// encode converts a typed value into the value kept in the store.
+	encode func(value interface{}) interface{}
+	// format converts a typed value into the string shown to the user and used in options.
+	format func(value interface{}) string
+	schema map[string]interface{}
+}
+
+type typedSetting struct {
+	baseSetting
+	store         SettingStore
+	kind          valueKind
+	choices       []*model.PostActionOptions
+	dataSource    string
+	dialogSubType string
+}
+
+// NewIntegerSetting creates a new setting holding an int64 between min and max, inclusive. It can
+// be edited through the panel dialog.
+func NewIntegerSetting(id, title, description, dependsOn string, min, max int64, store SettingStore, options ...SettingOption) Setting {
+	return &typedSetting{
+		baseSetting: newBaseSetting(id, title, description, dependsOn, options),
+		store:       store,
+		kind: valueKind{
+			parse: func(value interface{}) (interface{}, error) {
+				i, err := parseInteger(value)
+				if err != nil {
+					return nil, err
+				}
+				if i < min || i > max {
+					return nil, errors.Errorf("must be between %d and %d", min, max)
+				}
+				return i, nil
+			},
+			format: func(value interface{}) string {
+				return strconv.FormatInt(value.(int64), 10)
+			},
+			schema: map[string]interface{}{
+				"type":    "integer",
+				"minimum": min,
+				"maximum": max,
+			},
+		},
+		dialogSubType: "number",
+	}
+}
+
+// NewDurationSetting creates a new setting holding a time.Duration. If choices are given, the
+// duration is selected from them. Otherwise, it can be edited through the panel dialog, e.g. as
+// "1h30m".
+func NewDurationSetting(id, title, description, dependsOn string, choices []time.Duration, store SettingStore, options ...SettingOption) Setting {
+	kind := valueKind{
+		parse: parseDuration,
+		encode: func(value interface{}) interface{} {
+			return value.(time.Duration).String()
+		},
+		format: func(value interface{}) string {
+			return value.(time.Duration).String()
+		},
+		schema: map[string]interface{}{
+			"type":   "string",
+			"format": "duration",
+		},
+	}
+
+	var durationOptions []*model.PostActionOptions
+	if len(choices) > 0 {
+		enum := []string{}
+		for _, choice := range choices {
+			enum = append(enum, choice.String())
+			durationOptions = append(durationOptions, &model.PostActionOptions{Text: choice.String(), Value: choice.String()})
+		}
+		kind.schema["enum"] = enum
+	}
+
+	return &typedSetting{
+		baseSetting: newBaseSetting(id, title, description, dependsOn, options),
+		store:       store,
+		kind:        kind,
+		choices:     durationOptions,
+	}
+}
+
+// NewTimeOfDaySetting creates a new setting holding a time of the day formatted as "15:04",
+// selected from the times of the day every interval, starting at midnight.
+func NewTimeOfDaySetting(id, title, description, dependsOn string, interval time.Duration, store SettingStore, options ...SettingOption) Setting {
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+
+	choices := []*model.PostActionOptions{}
+	for t := time.Duration(0); t < 24*time.Hour; t += interval {
+		text := fmt.Sprintf("%02d:%02d", int(t.Hours()), int(t.Minutes())%60)
+		choices = append(choices, &model.PostActionOptions{Text: text, Value: text})
+	}
+
+	return &typedSetting{
+		baseSetting: newBaseSetting(id, title, description, dependsOn, options),
+		store:       store,
+		kind: valueKind{
+			parse: parseTimeOfDay,
+			format: func(value interface{}) string {
+				return value.(string)
+			},
+			schema: map[string]interface{}{
+				"type":    "string",
+				"pattern": `^([01][0-9]|2[0-3]):[0-5][0-9]$`,
+			},
+		},
+		choices: choices,
+	}
+}
+
+// NewTimezoneSetting creates a new setting holding an IANA timezone name, e.g. "Europe/Madrid".
+// If timezones are given, the timezone is selected from them. Otherwise, it can be edited
+// through the panel dialog.
+func NewTimezoneSetting(id, title, description, dependsOn string, timezones []string, store SettingStore, options ...SettingOption) Setting {
+	kind := valueKind{
+		parse: parseTimezone,
+		format: func(value interface{}) string {
+			return value.(string)
+		},
+		schema: map[string]interface{}{
+			"type":   "string",
+			"format": "timezone",
+		},
+	}
+	if len(timezones) > 0 {
+		kind.schema["enum"] = timezones
+	}
+
+	return &typedSetting{
+		baseSetting: newBaseSetting(id, title, description, dependsOn, options),
+		store:       store,
+		kind:        kind,
+		choices:     stringsToOptions(timezones),
+	}
+}
+
+// NewUserSetting creates a new setting holding the ID of a user, selected from the users of
+// the server.
+func NewUserSetting(id, title, description, dependsOn string, store SettingStore, options ...SettingOption) Setting {
+	return newIDSetting(id, title, description, dependsOn, dataSourceUsers, "user-id", store, options)
+}
+
+// NewChannelSetting creates a new setting holding the ID of a channel, selected from the
+// channels of the user.
+func NewChannelSetting(id, title, description, dependsOn string, store SettingStore, options ...SettingOption) Setting {
+	return newIDSetting(id, title, description, dependsOn, dataSourceChannels, "channel-id", store, options)
+}
+
+func newIDSetting(id, title, description, dependsOn, dataSource, format string, store SettingStore, options []SettingOption) Setting {
+	return &typedSetting{
+		baseSetting: newBaseSetting(id, title, description, dependsOn, options),
+		store:       store,
+		kind: valueKind{
+			parse: func(value interface{}) (interface{}, error) {
+				s, ok := value.(string)
+				if !ok || !model.IsValidId(s) {
+					return nil, errors.New("must be a valid ID")
+				}
+				return s, nil
+			},
+			format: func(value interface{}) string {
+				return value.(string)
+			},
+			schema: map[string]interface{}{
+				"type":   "string",
+				"format": format,
+			},
+		},
+		dataSource: dataSource,
+	}
+}
+
+func (s *typedSetting) Set(userID string, value interface{}) error {
+	if value == nil || value == "" {
+		return s.Reset(userID)
+	}
+
+	parsed, err := s.parse(value)
+	if err != nil {
+		return err
+	}
+
+	return s.store.SetSetting(userID, s.id, s.encode(parsed))
+}
+
+// Get returns the typed value of the setting, its default value if none has been stored, or nil
+// if there is no default value.
+func (s *typedSetting) Get(userID string) (interface{}, error) {
+	value, err := s.store.GetSetting(userID, s.id)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return s.GetDefault(), nil
+	}
+
+	parsed, err := s.kind.parse(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid stored value")
+	}
+
+	return parsed, nil
+}
+
+// GetDefault returns the typed default value of the setting, or nil if there is none.
+func (s *typedSetting) GetDefault() interface{} {
+	if s.defaultValue == nil {
+		return nil
+	}
+
+	parsed, err := s.kind.parse(s.defaultValue)
+	if err != nil {
+		return nil
+	}
+
+	return parsed
+}
+
+func (s *typedSetting) Reset(userID string) error {
+	return s.reset(s.store, userID)
+}
+
+func (s *typedSetting) GetSlackAttachments(userID, settingHandler string, disabled bool) (*model.SlackAttachment, error) {
+	title := fmt.Sprintf("Setting: %s", s.title)
+	currentValueMessage := DisabledString
+
+	actions := []*model.PostAction{}
+	if !disabled {
+		storedValue, err := s.store.GetSetting(userID, s.id)
+		if err != nil {
+			return nil, err
+		}
+
+		currentValue, err := s.Get(userID)
+		if err != nil {
+			return nil, err
+		}
+		currentValueMessage = fmt.Sprintf("Current value: %s", s.displayValue(currentValue))
+
+		if len(s.choices) > 0 || s.dataSource != "" {
+			actions = append(actions, &model.PostAction{
+				Name: "Select an option:",
+				Integration: &model.PostActionIntegration{
+					URL: settingHandler,
+					Context: map[string]interface{}{
+						ContextIDKey: s.id,
+					},
+				},
+				Type:       model.POST_ACTION_TYPE_SELECT,
+				Options:    s.choices,
+				DataSource: s.dataSource,
+			})
+		}
+
+		if storedValue != nil {
+			actions = append(actions, &model.PostAction{
+				Name: "Reset",
+				Integration: &model.PostActionIntegration{
+					URL: settingHandler,
+					Context: map[string]interface{}{
+						ContextIDKey:    s.id,
+						ContextResetKey: true,
+					},
+				},
+			})
+		}
+	}
+
+	text := fmt.Sprintf("%s\n%s", s.description, currentValueMessage)
+	sa := model.SlackAttachment{
+		Title:    title,
+		Text:     text,
+		Fallback: fmt.Sprintf("%s: %s", title, text),
+		Actions:  actions,
+	}
+
+	return &sa, nil
+}
+
+func (s *typedSetting) GetDialogElement(userID string) (*model.DialogElement, error) {
+	currentValue, err := s.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	element := &model.DialogElement{
+		DisplayName: s.title,
+		Name:        s.id,
+		Type:        "text",
+		SubType:     s.dialogSubType,
+		HelpText:    s.description,
+		Optional:    true,
+	}
+	if currentValue != nil {
+		element.Default = s.kind.format(currentValue)
+	}
+	if len(s.choices) > 0 || s.dataSource != "" {
+		element.Type = "select"
+		element.SubType = ""
+		element.Options = s.choices
+		element.DataSource = s.dataSource
+	}
+
+	return element, nil
+}
+
+func (s *typedSetting) ValidateDialogValue(value interface{}) string {
+	if value == nil || value == "" {
+		return ""
+	}
+
+	if _, err := s.parse(value); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+func (s *typedSetting) GetSchema() map[string]interface{} {
+	schema := s.baseSchema("")
+	for key, value := range s.kind.schema {
+		schema[key] = value
+	}
+	delete(schema, "default")
+	if defaultValue := s.GetDefault(); defaultValue != nil {
+		schema["default"] = s.encode(defaultValue)
+	}
+	return schema
+}
+
+func (s *typedSetting) IsDisabled(foreignValue interface{}) bool {
+	return foreignValue == FalseString
+}
+
+// parse parses the value and runs the validator of the setting.
+func (s *typedSetting) parse(value interface{}) (interface{}, error) {
+	parsed, err := s.kind.parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.runValidator(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
+func (s *typedSetting) encode(value interface{}) interface{} {
+	if s.kind.encode == nil {
+		return value
+	}
+	return s.kind.encode(value)
+}
+
+func (s *typedSetting) displayValue(value interface{}) string {
+	if value == nil {
+		return NotSetString
+	}
+
+	text := s.kind.format(value)
+	for _, o := range s.choices {
+		if o.Value == text {
+			return o.Text
+		}
+	}
+
+	switch s.dataSource {
+	case dataSourceUsers:
+		return fmt.Sprintf("<@%s>", text)
+	case dataSourceChannels:
+		return fmt.Sprintf("~%s", text)
+	}
+
+	return text
+}
+
+func parseInteger(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, errors.New("must be an integer")
+		}
+		return int64(v), nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return 0, errors.New("must be an integer")
+		}
+		return i, nil
+	}
+
+	return 0, errors.New("must be an integer")
+}
+
+func parseDuration(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.New("must be a duration, e.g. 1h30m")
+		}
+		return d, nil
+	}
+
+	return nil, errors.New("must be a duration, e.g. 1h30m")
+}
+
+func parseTimeOfDay(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("must be a time of the day, e.g. 09:30")
+	}
+
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New("must be a time of the day, e.g. 09:30")
+	}
+
+	return t.Format("15:04"), nil
+}
+
+func parseTimezone(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("must be a timezone, e.g. Europe/Madrid")
+	}
+
+	if _, err := time.LoadLocation(s); err != nil || s == "" || s == "Local" {
+		return nil, errors.New("must be a timezone, e.g. Europe/Madrid")
+	}
+
+	return s, nil
+}
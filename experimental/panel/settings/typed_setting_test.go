@@ -0,0 +1,161 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonStore stores settings as JSON, the way a KV store based implementation does.
+type jsonStore map[string][]byte
+
+func (s jsonStore) SetSetting(userID, settingID string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s[userID+settingID] = b
+	return nil
+}
+
+func (s jsonStore) GetSetting(userID, settingID string) (interface{}, error) {
+	b, ok := s[userID+settingID]
+	if !ok {
+		return nil, nil
+	}
+	var value interface{}
+	err := json.Unmarshal(b, &value)
+	return value, err
+}
+
+func (s jsonStore) DeleteSetting(userID, settingID string) error {
+	delete(s, userID+settingID)
+	return nil
+}
+
+func TestTypedSettings(t *testing.T) {
+	userID := model.NewId()
+
+	t.Run("integer", func(t *testing.T) {
+		s := NewIntegerSetting("count", "Count", "", "", 1, 10, jsonStore{}, WithDefault(5)).(TypedSetting)
+
+		value, err := s.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), value)
+
+		require.NoError(t, s.Set(userID, "7"))
+		value, err = s.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), value)
+
+		require.EqualError(t, s.Set(userID, "11"), "must be between 1 and 10")
+		require.EqualError(t, s.Set(userID, "seven"), "must be an integer")
+
+		require.NoError(t, s.Reset(userID))
+		value, err = s.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), value)
+	})
+
+	t.Run("duration with validator", func(t *testing.T) {
+		s := NewDurationSetting("snooze", "Snooze", "", "", nil, jsonStore{}, WithValidator(func(value interface{}) error {
+			if value.(time.Duration) < time.Minute {
+				return errors.New("must be at least a minute")
+			}
+			return nil
+		}))
+
+		value, err := s.Get(userID)
+		require.NoError(t, err)
+		assert.Nil(t, value)
+
+		require.NoError(t, s.Set(userID, "1h30m"))
+		value, err = s.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, 90*time.Minute, value)
+
+		require.EqualError(t, s.Set(userID, "30s"), "must be at least a minute")
+		assert.Equal(t, "must be a duration, e.g. 1h30m", s.(DialogSetting).ValidateDialogValue("soon"))
+	})
+
+	t.Run("time of day", func(t *testing.T) {
+		s := NewTimeOfDaySetting("time", "Time", "", "", time.Hour, jsonStore{}, WithDefault("9:00"))
+
+		value, err := s.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, "09:00", value)
+
+		sa, err := s.GetSlackAttachments(userID, "/settings", false)
+		require.NoError(t, err)
+		require.Len(t, sa.Actions, 1)
+		assert.Len(t, sa.Actions[0].Options, 24)
+
+		require.NoError(t, s.Set(userID, "17:00"))
+		require.Error(t, s.Set(userID, "25:00"))
+
+		sa, err = s.GetSlackAttachments(userID, "/settings", false)
+		require.NoError(t, err)
+		require.Len(t, sa.Actions, 2)
+		assert.Equal(t, true, sa.Actions[1].Integration.Context[ContextResetKey])
+	})
+
+	t.Run("timezone", func(t *testing.T) {
+		s := NewTimezoneSetting("tz", "Timezone", "", "", nil, jsonStore{})
+
+		require.NoError(t, s.Set(userID, "UTC"))
+		require.Error(t, s.Set(userID, "Mars/Olympus"))
+
+		element, err := s.(DialogSetting).GetDialogElement(userID)
+		require.NoError(t, err)
+		assert.Equal(t, "text", element.Type)
+		assert.Equal(t, "UTC", element.Default)
+	})
+
+	t.Run("user and channel", func(t *testing.T) {
+		user := NewUserSetting("user", "User", "", "", jsonStore{})
+		channel := NewChannelSetting("channel", "Channel", "", "", jsonStore{})
+
+		require.Error(t, user.Set(userID, "not-an-id"))
+		require.NoError(t, user.Set(userID, userID))
+
+		sa, err := channel.GetSlackAttachments(userID, "/settings", false)
+		require.NoError(t, err)
+		require.Len(t, sa.Actions, 1)
+		assert.Equal(t, "channels", sa.Actions[0].DataSource)
+	})
+
+	t.Run("option default", func(t *testing.T) {
+		s := NewOptionSetting("option", "Option", "", "", []string{"a", "b"}, jsonStore{})
+
+		value, err := s.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, "", value)
+
+		s = NewOptionSetting("option", "Option", "", "", []string{"a", "b"}, jsonStore{}, WithDefault("b"))
+		value, err = s.Get(userID)
+		require.NoError(t, err)
+		assert.Equal(t, "b", value)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		s := NewIntegerSetting("count", "Count", "How many", "enabled", 1, 10, jsonStore{}, WithDefault(5)).(SchemaSetting)
+		assert.Equal(t, map[string]interface{}{
+			"type":         "integer",
+			"title":        "Count",
+			"description":  "How many",
+			"default":      int64(5),
+			"minimum":      int64(1),
+			"maximum":      int64(10),
+			"x-depends-on": "enabled",
+		}, s.GetSchema())
+
+		d := NewDurationSetting("snooze", "Snooze", "", "", []time.Duration{time.Hour}, jsonStore{}, WithDefault(time.Hour)).(SchemaSetting)
+		assert.Equal(t, "1h0m0s", d.GetSchema()["default"])
+		assert.Equal(t, []string{"1h0m0s"}, d.GetSchema()["enum"])
+	})
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License for license information.
+
+package postscheduler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+)
+
+const (
+	// DefaultKeyPrefix is the prefix used for the job keys and the KVStore keys by default.
+	DefaultKeyPrefix = "schedpost_"
+)
+
+// ErrNotAuthor is returned when a user tries to modify a scheduled post they did not author.
+var ErrNotAuthor = errors.New("scheduled post belongs to another user")
+
+// ScheduledPost is a post waiting to be created at a given time.
+type ScheduledPost struct {
+	ID        string
+	UserID    string
+	ChannelID string
+	RootID    string
+	Message   string
+	Props     model.StringInterface
+	PostAt    time.Time
+	CreateAt  time.Time
+}
+
+// JobScheduler defines the portion of the cluster.JobOnceScheduler used by the Scheduler.
+type JobScheduler interface {
+	ScheduleOnce(key string, runAt time.Time) (*cluster.JobOnce, error)
+	Cancel(key string)
+}
+
+// Scheduler persists posts to be created at a later time, and creates them once across the
+// cluster when their time comes.
+type Scheduler interface {
+	// Schedule stores the post and schedules its creation. The ID and CreateAt fields are set on
+	// the given post.
+	Schedule(post *ScheduledPost) error
+	// Get returns the scheduled post with the given ID, or common.ErrNotFound if it does not exist.
+	Get(id string) (*ScheduledPost, error)
+	// List returns the posts scheduled by the given user, ordered by PostAt.
+	List(userID string) ([]*ScheduledPost, error)
+	// Update changes the message, props and time of a scheduled post authored by the given user.
+	Update(userID string, post *ScheduledPost) error
+	// Cancel removes a scheduled post authored by the given user.
+	Cancel(userID, id string) error
+	// HandleJob creates the scheduled post for the given job key. It returns false if the key
+	// does not belong to this Scheduler, so that plugins can share a JobOnceScheduler callback.
+	HandleJob(key string) bool
+}
+
+type scheduler struct {
+	client    *pluginapi.Client
+	jobs      JobScheduler
+	store     *store
+	logger    logger.Logger
+	keyPrefix string
+}
+
+/*
+New creates a new Scheduler.
+
+- client: The plugin API client used to create the posts and store the scheduled posts.
+
+- jobs: The scheduler running the jobs, usually returned by cluster.GetJobOnceScheduler.
+The plugin must call HandleJob from the JobOnceScheduler callback, e.g.:
+
+	_ = jobs.SetCallback(func(key string) {
+		if postScheduler.HandleJob(key) {
+			return
+		}
+		// handle other jobs
+	})
+
+- l Logger: A logger to log posts that could not be created.
+
+- keyPrefix: The prefix used for job and KVStore keys. Defaults to DefaultKeyPrefix if empty.
+*/
+func New(client *pluginapi.Client, jobs JobScheduler, l logger.Logger, keyPrefix string) Scheduler {
+	if keyPrefix == "" {
+		keyPrefix = DefaultKeyPrefix
+	}
+
+	return &scheduler{
+		client:    client,
+		jobs:      jobs,
+		store:     newStore(&client.KV, keyPrefix),
+		logger:    l,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *scheduler) Schedule(post *ScheduledPost) error {
+	if post.UserID == "" || post.ChannelID == "" {
+		return errors.New("scheduled post must have a user and a channel")
+	}
+	if post.PostAt.IsZero() {
+		return errors.New("scheduled post must have a time")
+	}
+
+	post.ID = model.NewId()
+	post.CreateAt = time.Now()
+
+	if err := s.store.save(post); err != nil {
+		return errors.Wrap(err, "failed to store scheduled post")
+	}
+
+	if _, err := s.jobs.ScheduleOnce(s.jobKey(post.ID), post.PostAt); err != nil {
+		if deleteErr := s.store.delete(post); deleteErr != nil {
+			s.logger.WithError(deleteErr).Warnf("Failed to remove unscheduled post %s", post.ID)
+		}
+		return errors.Wrap(err, "failed to schedule post")
+	}
+
+	return nil
+}
+
+func (s *scheduler) Get(id string) (*ScheduledPost, error) {
+	return s.store.get(id)
+}
+
+func (s *scheduler) List(userID string) ([]*ScheduledPost, error) {
+	return s.store.list(userID)
+}
+
+func (s *scheduler) Update(userID string, post *ScheduledPost) error {
+	stored, err := s.getAuthored(userID, post.ID)
+	if err != nil {
+		return err
+	}
+
+	rescheduled := !post.PostAt.IsZero() && !post.PostAt.Equal(stored.PostAt)
+
+	stored.Message = post.Message
+	stored.Props = post.Props
+	if rescheduled {
+		stored.PostAt = post.PostAt
+	}
+
+	if err = s.store.save(stored); err != nil {
+		return errors.Wrap(err, "failed to store scheduled post")
+	}
+
+	if rescheduled {
+		s.jobs.Cancel(s.jobKey(stored.ID))
+		if _, err = s.jobs.ScheduleOnce(s.jobKey(stored.ID), stored.PostAt); err != nil {
+			return errors.Wrap(err, "failed to reschedule post")
+		}
+	}
+
+	*post = *stored
+
+	return nil
+}
+
+func (s *scheduler) Cancel(userID, id string) error {
+	stored, err := s.getAuthored(userID, id)
+	if err != nil {
+		return err
+	}
+
+	s.jobs.Cancel(s.jobKey(id))
+
+	return s.store.delete(stored)
+}
+
+func (s *scheduler) HandleJob(key string) bool {
+	if !strings.HasPrefix(key, s.keyPrefix) {
+		return false
+	}
+
+	id := strings.TrimPrefix(key, s.keyPrefix)
+	l := s.logger.With(logger.LogContext{"scheduled_post_id": id})
+
+	post, err := s.store.get(id)
+	if err != nil {
+		l.WithError(err).Errorf("Failed to get scheduled post")
+		return true
+	}
+
+	defer func() {
+		if err := s.store.delete(post); err != nil {
+			l.WithError(err).Warnf("Failed to remove fired scheduled post")
+		}
+	}()
+
+	user, err := s.client.User.Get(post.UserID)
+	if err != nil {
+		l.WithError(err).Errorf("Failed to get scheduled post author")
+		return true
+	}
+	if user.DeleteAt != 0 {
+		l.Infof("Skipping scheduled post, as its author was deactivated")
+		return true
+	}
+
+	channel, err := s.client.Channel.Get(post.ChannelID)
+	if err != nil {
+		l.WithError(err).Errorf("Failed to get scheduled post channel")
+		return true
+	}
+	if channel.DeleteAt != 0 {
+		l.Infof("Skipping scheduled post, as its channel was deleted")
+		return true
+	}
+
+	err = s.client.Post.CreatePost(&model.Post{
+		UserId:    post.UserID,
+		ChannelId: post.ChannelID,
+		RootId:    post.RootID,
+		Message:   post.Message,
+		Props:     post.Props,
+	})
+	if err != nil {
+		l.WithError(err).Errorf("Failed to create scheduled post")
+	}
+
+	return true
+}
+
+func (s *scheduler) getAuthored(userID, id string) (*ScheduledPost, error) {
+	stored, err := s.store.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.UserID != userID {
+		return nil, ErrNotAuthor
+	}
+
+	return stored, nil
+}
+
+func (s *scheduler) jobKey(id string) string {
+	return s.keyPrefix + id
+}
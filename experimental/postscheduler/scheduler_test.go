@@ -0,0 +1,195 @@
+package postscheduler
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/cluster"
+	"github.com/mattermost/mattermost-plugin-api/experimental/bot/logger"
+	"github.com/mattermost/mattermost-plugin-api/experimental/common"
+)
+
+type fakeJobs struct {
+	scheduled map[string]time.Time
+}
+
+func (j *fakeJobs) ScheduleOnce(key string, runAt time.Time) (*cluster.JobOnce, error) {
+	j.scheduled[key] = runAt
+	return nil, nil
+}
+
+func (j *fakeJobs) Cancel(key string) {
+	delete(j.scheduled, key)
+}
+
+func setupAPI() *plugintest.API {
+	var mu sync.Mutex
+	kv := map[string][]byte{}
+
+	api := &plugintest.API{}
+	api.On("KVGet", mock.AnythingOfType("string")).Return(
+		func(key string) []byte {
+			mu.Lock()
+			defer mu.Unlock()
+			return kv[key]
+		},
+		nil,
+	)
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(
+		func(key string, value []byte, options model.PluginKVSetOptions) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if options.Atomic && !bytes.Equal(kv[key], options.OldValue) {
+				return false
+			}
+			if value == nil {
+				delete(kv, key)
+			} else {
+				kv[key] = value
+			}
+			return true
+		},
+		nil,
+	)
+
+	return api
+}
+
+func setupScheduler(api *plugintest.API) (Scheduler, *fakeJobs) {
+	jobs := &fakeJobs{scheduled: map[string]time.Time{}}
+	client := pluginapi.NewClient(api, &plugintest.Driver{})
+	return New(client, jobs, logger.NewNilLogger(), ""), jobs
+}
+
+func TestSchedule(t *testing.T) {
+	userID := model.NewId()
+	channelID := model.NewId()
+
+	t.Run("schedule, list and cancel", func(t *testing.T) {
+		s, jobs := setupScheduler(setupAPI())
+
+		later := &ScheduledPost{UserID: userID, ChannelID: channelID, Message: "later", PostAt: time.Now().Add(2 * time.Hour)}
+		sooner := &ScheduledPost{UserID: userID, ChannelID: channelID, Message: "sooner", PostAt: time.Now().Add(time.Hour)}
+		require.NoError(t, s.Schedule(later))
+		require.NoError(t, s.Schedule(sooner))
+		assert.NotEmpty(t, later.ID)
+		assert.Len(t, jobs.scheduled, 2)
+
+		posts, err := s.List(userID)
+		require.NoError(t, err)
+		require.Len(t, posts, 2)
+		assert.Equal(t, "sooner", posts[0].Message)
+		assert.Equal(t, "later", posts[1].Message)
+
+		err = s.Cancel("another-user", later.ID)
+		require.Equal(t, ErrNotAuthor, err)
+
+		require.NoError(t, s.Cancel(userID, later.ID))
+		assert.Len(t, jobs.scheduled, 1)
+
+		_, err = s.Get(later.ID)
+		require.Equal(t, common.ErrNotFound, err)
+
+		posts, err = s.List(userID)
+		require.NoError(t, err)
+		require.Len(t, posts, 1)
+		assert.Equal(t, sooner.ID, posts[0].ID)
+	})
+
+	t.Run("invalid post", func(t *testing.T) {
+		s, _ := setupScheduler(setupAPI())
+
+		require.Error(t, s.Schedule(&ScheduledPost{UserID: userID, PostAt: time.Now()}))
+		require.Error(t, s.Schedule(&ScheduledPost{UserID: userID, ChannelID: channelID}))
+	})
+
+	t.Run("update reschedules the job", func(t *testing.T) {
+		s, jobs := setupScheduler(setupAPI())
+
+		post := &ScheduledPost{UserID: userID, ChannelID: channelID, Message: "hello", PostAt: time.Now().Add(time.Hour)}
+		require.NoError(t, s.Schedule(post))
+
+		newPostAt := time.Now().Add(3 * time.Hour)
+		update := &ScheduledPost{ID: post.ID, Message: "edited", PostAt: newPostAt}
+		require.NoError(t, s.Update(userID, update))
+		assert.Equal(t, channelID, update.ChannelID)
+
+		stored, err := s.Get(post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "edited", stored.Message)
+		assert.True(t, newPostAt.Equal(stored.PostAt))
+		assert.True(t, newPostAt.Equal(jobs.scheduled[DefaultKeyPrefix+post.ID]))
+	})
+}
+
+func TestHandleJob(t *testing.T) {
+	userID := model.NewId()
+	channelID := model.NewId()
+
+	t.Run("unknown key", func(t *testing.T) {
+		s, _ := setupScheduler(setupAPI())
+		assert.False(t, s.HandleJob("another_job"))
+	})
+
+	t.Run("creates the post", func(t *testing.T) {
+		api := setupAPI()
+		defer api.AssertExpectations(t)
+		s, _ := setupScheduler(api)
+
+		post := &ScheduledPost{UserID: userID, ChannelID: channelID, Message: "hello", PostAt: time.Now()}
+		require.NoError(t, s.Schedule(post))
+
+		api.On("GetUser", userID).Return(&model.User{Id: userID}, nil)
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID}, nil)
+		created := &model.Post{UserId: userID, ChannelId: channelID, Message: "hello"}
+		api.On("CreatePost", created).Return(created, nil)
+
+		assert.True(t, s.HandleJob(DefaultKeyPrefix+post.ID))
+
+		_, err := s.Get(post.ID)
+		require.Equal(t, common.ErrNotFound, err)
+	})
+
+	t.Run("skips deleted channels", func(t *testing.T) {
+		api := setupAPI()
+		defer api.AssertExpectations(t)
+		s, _ := setupScheduler(api)
+
+		post := &ScheduledPost{UserID: userID, ChannelID: channelID, Message: "hello", PostAt: time.Now()}
+		require.NoError(t, s.Schedule(post))
+
+		api.On("GetUser", userID).Return(&model.User{Id: userID}, nil)
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, DeleteAt: 1}, nil)
+
+		assert.True(t, s.HandleJob(DefaultKeyPrefix+post.ID))
+
+		posts, err := s.List(userID)
+		require.NoError(t, err)
+		assert.Empty(t, posts)
+	})
+
+	t.Run("skips deactivated users", func(t *testing.T) {
+		api := setupAPI()
+		defer api.AssertExpectations(t)
+		s, _ := setupScheduler(api)
+
+		post := &ScheduledPost{UserID: userID, ChannelID: channelID, Message: "hello", PostAt: time.Now()}
+		require.NoError(t, s.Schedule(post))
+
+		api.On("GetUser", userID).Return(&model.User{Id: userID, DeleteAt: 1}, nil)
+
+		assert.True(t, s.HandleJob(DefaultKeyPrefix+post.ID))
+
+		_, err := s.Get(post.ID)
+		require.Equal(t, common.ErrNotFound, err)
+	})
+}
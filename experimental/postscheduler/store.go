@@ -0,0 +1,122 @@
+package postscheduler
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-api/experimental/common"
+)
+
+type store struct {
+	kv        *pluginapi.KVService
+	keyPrefix string
+}
+
+func newStore(kv *pluginapi.KVService, keyPrefix string) *store {
+	return &store{
+		kv:        kv,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *store) get(id string) (*ScheduledPost, error) {
+	var post *ScheduledPost
+	err := s.kv.Get(s.getPostKey(id), &post)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, common.ErrNotFound
+	}
+	return post, nil
+}
+
+func (s *store) list(userID string) ([]*ScheduledPost, error) {
+	var ids []string
+	err := s.kv.Get(s.getUserKey(userID), &ids)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*ScheduledPost, 0, len(ids))
+	for _, id := range ids {
+		post, err := s.get(id)
+		if err == common.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].PostAt.Before(posts[j].PostAt)
+	})
+
+	return posts, nil
+}
+
+func (s *store) save(post *ScheduledPost) error {
+	ok, err := s.kv.Set(s.getPostKey(post.ID), post)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("value not set without errors")
+	}
+
+	return s.updateUserIndex(post.UserID, func(ids []string) []string {
+		for _, id := range ids {
+			if id == post.ID {
+				return ids
+			}
+		}
+		return append(ids, post.ID)
+	})
+}
+
+func (s *store) delete(post *ScheduledPost) error {
+	err := s.kv.Delete(s.getPostKey(post.ID))
+	if err != nil {
+		return err
+	}
+
+	return s.updateUserIndex(post.UserID, func(ids []string) []string {
+		for i, id := range ids {
+			if id == post.ID {
+				return append(ids[:i], ids[i+1:]...)
+			}
+		}
+		return ids
+	})
+}
+
+func (s *store) updateUserIndex(userID string, update func(ids []string) []string) error {
+	return s.kv.SetAtomicWithRetries(s.getUserKey(userID), func(oldValue []byte) (interface{}, error) {
+		var ids []string
+		if len(oldValue) > 0 {
+			if err := json.Unmarshal(oldValue, &ids); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal scheduled post index")
+			}
+		}
+
+		ids = update(ids)
+		if len(ids) == 0 {
+			return nil, nil
+		}
+
+		return ids, nil
+	})
+}
+
+func (s *store) getPostKey(id string) string {
+	return s.keyPrefix + "post_" + id
+}
+
+func (s *store) getUserKey(userID string) string {
+	return s.keyPrefix + "user_" + userID
+}
@@ -0,0 +1,143 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func setupKV() *plugintest.API {
+	var mu sync.Mutex
+	kv := map[string][]byte{}
+
+	api := &plugintest.API{}
+	api.On("KVGet", mock.AnythingOfType("string")).Return(
+		func(key string) []byte {
+			mu.Lock()
+			defer mu.Unlock()
+			return kv[key]
+		},
+		nil,
+	)
+	api.On("KVSetWithOptions", mock.AnythingOfType("string"), mock.Anything, mock.AnythingOfType("model.PluginKVSetOptions")).Return(
+		func(key string, value []byte, options model.PluginKVSetOptions) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			kv[key] = value
+			return true
+		},
+		nil,
+	)
+
+	return api
+}
+
+type failingClient struct{}
+
+func (failingClient) Enqueue(t Track) error {
+	return errors.New("unreachable")
+}
+
+func (failingClient) Close() error {
+	return nil
+}
+
+func TestFileClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telemetry")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "telemetry.jsonl")
+	c, err := NewFileClient(path)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Enqueue(Track{UserID: "server", Event: "start", Properties: map[string]interface{}{"count": 1}}))
+	require.NoError(t, c.Enqueue(Track{UserID: "server", Event: "stop"}))
+	require.NoError(t, c.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	events := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var track fileTrack
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &track))
+		assert.Equal(t, "server", track.UserID)
+		assert.False(t, track.Timestamp.IsZero())
+		events = append(events, track.Event)
+	}
+	assert.Equal(t, []string{"start", "stop"}, events)
+}
+
+func TestKVClient(t *testing.T) {
+	client := pluginapi.NewClient(setupKV(), &plugintest.Driver{})
+	c := NewKVClient(*client, "telemetry").(*kvClient)
+
+	day := time.Date(2021, 3, 4, 23, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return day }
+	require.NoError(t, c.Enqueue(Track{Event: "start"}))
+	require.NoError(t, c.Enqueue(Track{Event: "start"}))
+	require.NoError(t, c.Enqueue(Track{Event: "stop"}))
+
+	c.now = func() time.Time { return day.Add(2 * time.Hour) }
+	require.NoError(t, c.Enqueue(Track{Event: "start"}))
+
+	counts, err := c.Counts(day)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"start": 2, "stop": 1}, counts)
+
+	count, err := c.Count("start", day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	counts, err = c.Counts(day.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func TestMultiClient(t *testing.T) {
+	first := NewTestClient()
+	second := NewTestClient()
+	c := NewMultiClient(first, nil, failingClient{}, second)
+
+	err := c.Enqueue(Track{Event: "start"})
+	require.EqualError(t, err, "1 of 3 clients failed: unreachable")
+	assert.Equal(t, []string{"start"}, first.Events())
+	assert.Equal(t, []string{"start"}, second.Events())
+
+	require.NoError(t, c.Close())
+	assert.True(t, first.IsClosed())
+	assert.True(t, second.IsClosed())
+}
+
+func TestTrackerWithTestClient(t *testing.T) {
+	c := NewTestClient()
+	tracker := NewTracker(c, "diagnostic-id", "5.30.0", "com.example.plugin", "1.0.0", "example", true)
+
+	require.NoError(t, tracker.TrackUserEvent("start", "user-id", nil))
+
+	tracks := c.Tracks()
+	require.Len(t, tracks, 1)
+	assert.Equal(t, "example_start", tracks[0].Event)
+	assert.Equal(t, "diagnostic-id", tracks[0].UserID)
+	assert.Equal(t, "user-id", tracks[0].Properties["UserActualID"])
+
+	c.Reset()
+	assert.Empty(t, c.Tracks())
+}
@@ -31,15 +31,21 @@
 //	    p.API.LogWarn("telemetry client not started", "error", err.Error())
 //	  }
 //  }
-// 3. Init and update the tracker on configuration change
+// 3. Init the tracker on the first configuration change, and update it on the next ones
 //  func (p *Plugin) OnConfigurationChange() error {
 //    ...
-//    p.tracker = telemetry.NewTracker(p.telemetryClient, p.API.GetDiagnosticId(), p.API.GetServerVersion(), manifest.Id,
-//      manifest.Version, "pluginName", enableDiagnostics, logger)
+//    if p.tracker == nil {
+//      p.tracker = telemetry.NewTracker(p.telemetryClient, p.API.GetDiagnosticId(), p.API.GetServerVersion(), manifest.Id,
+//        manifest.Version, "pluginName", enableDiagnostics, telemetry.WithDeniedProperties("Message"))
+//    }
+//    p.tracker.OnConfigurationChange(p.API.GetConfig())
 //    return nil
 //  }
-// 4. Close the client on plugin deactivate
+// 4. Flush the tracker and close the client on plugin deactivate
 //  func (p *Plugin) OnDeactivate() error {
+//	  if p.tracker != nil {
+//	    _ = p.tracker.Flush()
+//	  }
 //	  if p.telemetryClient != nil {
 //	    err := p.telemetryClient.Close()
 //	    if err != nil {
@@ -48,4 +54,10 @@
 //	  }
 //	  return nil
 //  }
+//
+// Besides Rudder, other clients are available for servers that cannot reach the data plane:
+//  - NewFileClient appends every track as a JSON line to a local file.
+//  - NewKVClient counts the events per day in the KV store, where they can be queried.
+//  - NewMultiClient sends every track to several clients.
+//  - NewTestClient records the tracks in memory, to assert them in tests.
 package telemetry
@@ -0,0 +1,67 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// fileTrack is the JSON representation of a track written by the file client.
+type fileTrack struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	UserID     string                 `json:"user_id"`
+	Event      string                 `json:"event"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type fileClient struct {
+	lock sync.Mutex
+	file *os.File
+	now  func() time.Time
+}
+
+// NewFileClient creates a telemetry client appending every track, as a JSON line, to the file at
+// the given path. It lets servers without internet access keep their usage metrics locally.
+func NewFileClient(path string) (Client, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot open the telemetry file")
+	}
+
+	return &fileClient{
+		file: file,
+		now:  time.Now,
+	}, nil
+}
+
+func (c *fileClient) Enqueue(t Track) error {
+	b, err := json.Marshal(fileTrack{
+		Timestamp:  c.now().UTC(),
+		UserID:     t.UserID,
+		Event:      t.Event,
+		Properties: t.Properties,
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal the track")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, err = c.file.Write(append(b, '\n'))
+	if err != nil {
+		return errors.Wrap(err, "cannot write the track")
+	}
+
+	return nil
+}
+
+func (c *fileClient) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.file.Close()
+}
@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+const dayLayout = "2006-01-02"
+
+// KVClient is a telemetry client counting the events per day in the KV store of the plugin,
+// instead of sending them anywhere. The counts can be queried, e.g. to show them to the system
+// admins or to export them manually.
+type KVClient interface {
+	Client
+	// Counts returns the number of times each event was tracked on the given day, in UTC.
+	Counts(day time.Time) (map[string]int64, error)
+	// Count returns the number of times the event was tracked from one day to another, both
+	// included, in UTC.
+	Count(event string, from, to time.Time) (int64, error)
+}
+
+type kvClient struct {
+	client    pluginapi.Client
+	keyPrefix string
+	now       func() time.Time
+}
+
+// NewKVClient creates a new KVClient storing the counts under keys starting with keyPrefix.
+func NewKVClient(apiClient pluginapi.Client, keyPrefix string) KVClient {
+	return &kvClient{
+		client:    apiClient,
+		keyPrefix: keyPrefix,
+		now:       time.Now,
+	}
+}
+
+func (c *kvClient) Enqueue(t Track) error {
+	err := c.client.KV.SetAtomicWithRetries(c.getKey(c.now()), func(oldValue []byte) (interface{}, error) {
+		counts := map[string]int64{}
+		if len(oldValue) > 0 {
+			err := json.Unmarshal(oldValue, &counts)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		counts[t.Event]++
+		return counts, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot count the track")
+	}
+
+	return nil
+}
+
+func (c *kvClient) Close() error {
+	return nil
+}
+
+func (c *kvClient) Counts(day time.Time) (map[string]int64, error) {
+	counts := map[string]int64{}
+	err := c.client.KV.Get(c.getKey(day), &counts)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get the counts")
+	}
+
+	return counts, nil
+}
+
+func (c *kvClient) Count(event string, from, to time.Time) (int64, error) {
+	var total int64
+	last := to.UTC().Format(dayLayout)
+	for day := from.UTC(); day.Format(dayLayout) <= last; day = day.AddDate(0, 0, 1) {
+		counts, err := c.Counts(day)
+		if err != nil {
+			return 0, err
+		}
+		total += counts[event]
+	}
+
+	return total, nil
+}
+
+func (c *kvClient) getKey(day time.Time) string {
+	return c.keyPrefix + "-" + day.UTC().Format(dayLayout)
+}
@@ -0,0 +1,61 @@
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type multiClient struct {
+	clients []Client
+}
+
+// NewMultiClient creates a telemetry client sending every track to all the given clients, e.g.
+// to Rudder and to a local file. Nil clients are ignored.
+func NewMultiClient(clients ...Client) Client {
+	mc := &multiClient{}
+	for _, c := range clients {
+		if c != nil {
+			mc.clients = append(mc.clients, c)
+		}
+	}
+
+	return mc
+}
+
+func (mc *multiClient) Enqueue(t Track) error {
+	errs := []error{}
+	for _, c := range mc.clients {
+		err := c.Enqueue(t)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return combineErrors(errs, len(mc.clients))
+}
+
+func (mc *multiClient) Close() error {
+	errs := []error{}
+	for _, c := range mc.clients {
+		err := c.Close()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return combineErrors(errs, len(mc.clients))
+}
+
+func combineErrors(errs []error, total int) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return errors.Errorf("%d of %d clients failed: %s", len(errs), total, strings.Join(messages, "; "))
+}
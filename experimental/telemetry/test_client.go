@@ -0,0 +1,71 @@
+package telemetry
+
+import "sync"
+
+// TestClient is a telemetry client recording the tracks in memory, so the telemetry of a plugin
+// can be asserted in its tests.
+type TestClient struct {
+	lock   sync.Mutex
+	tracks []Track
+	closed bool
+}
+
+// NewTestClient creates a new TestClient.
+func NewTestClient() *TestClient {
+	return &TestClient{}
+}
+
+// Enqueue records the track.
+func (c *TestClient) Enqueue(t Track) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.tracks = append(c.tracks, t)
+	return nil
+}
+
+// Close marks the client as closed.
+func (c *TestClient) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+// Tracks returns the recorded tracks, in the order they were enqueued.
+func (c *TestClient) Tracks() []Track {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]Track{}, c.tracks...)
+}
+
+// Events returns the names of the recorded events, in the order they were enqueued.
+func (c *TestClient) Events() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	events := []string{}
+	for _, t := range c.tracks {
+		events = append(events, t.Event)
+	}
+	return events
+}
+
+// IsClosed returns true if Close was called.
+func (c *TestClient) IsClosed() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.closed
+}
+
+// Reset forgets the recorded tracks.
+func (c *TestClient) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.tracks = nil
+	c.closed = false
+}
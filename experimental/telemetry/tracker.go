@@ -1,6 +1,13 @@
 package telemetry
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/pkg/errors"
 )
 
@@ -10,6 +17,10 @@ type Tracker interface {
 	TrackEvent(event string, properties map[string]interface{}) error
 	// TrackUserEvent registers an event through the configured telemetry client associated to a user
 	TrackUserEvent(event string, userID string, properties map[string]interface{}) error
+	// OnConfigurationChange enables or disables the tracker following the diagnostics setting of the server
+	OnConfigurationChange(config *model.Config)
+	// Flush enqueues the events kept by batching, if any
+	Flush() error
 }
 
 // Client defines a telemetry client
@@ -27,6 +38,57 @@ type Track struct {
 	Properties map[string]interface{}
 }
 
+// TrackerOption defines each option that can be passed in the creation of the Tracker.
+type TrackerOption func(*tracker)
+
+// WithAllowedProperties only sends the given event properties, dropping any other.
+func WithAllowedProperties(properties ...string) TrackerOption {
+	return func(t *tracker) {
+		t.allowed = toSet(properties)
+	}
+}
+
+// WithDeniedProperties drops the given event properties.
+func WithDeniedProperties(properties ...string) TrackerOption {
+	return func(t *tracker) {
+		t.denied = toSet(properties)
+	}
+}
+
+// WithHashing replaces the string properties looking like user IDs or emails, including the user
+// of TrackUserEvent, by their SHA-256 hash with the given salt.
+func WithHashing(salt string) TrackerOption {
+	return func(t *tracker) {
+		t.hashing = true
+		t.salt = salt
+	}
+}
+
+// WithSampling only sends the given fraction, between 0 and 1, of each event. Events are keyed
+// by the name given to TrackEvent, without the plugin short name. Other events are always sent.
+func WithSampling(rates map[string]float64) TrackerOption {
+	return func(t *tracker) {
+		t.sampling = rates
+	}
+}
+
+// WithDailyCap sends at most the given number of events per day, in UTC. The count is kept in
+// memory, so it is per plugin instance and restarts with the plugin.
+func WithDailyCap(maxEvents int) TrackerOption {
+	return func(t *tracker) {
+		t.dailyCap = maxEvents
+	}
+}
+
+// WithBatching keeps the events until there are size of them, or until interval has passed since
+// the first one kept. Call Flush to enqueue the events left, e.g. on deactivation.
+func WithBatching(size int, interval time.Duration) TrackerOption {
+	return func(t *tracker) {
+		t.batchSize = size
+		t.batchInterval = interval
+	}
+}
+
 type tracker struct {
 	client             Client
 	diagnosticID       string
@@ -34,7 +96,28 @@ type tracker struct {
 	pluginID           string
 	pluginVersion      string
 	telemetryShortName string
-	enabled            bool
+
+	lock    sync.Mutex
+	enabled bool
+
+	allowed  map[string]bool
+	denied   map[string]bool
+	hashing  bool
+	salt     string
+	sampling map[string]float64
+	random   func() float64
+
+	dailyCap int
+	capDay   string
+	capCount int
+
+	batchSize     int
+	batchInterval time.Duration
+	batch         []Track
+	batchStart    time.Time
+	batchTimer    *time.Timer
+
+	now func() time.Time
 }
 
 // NewTracker creates a default Tracker
@@ -46,7 +129,8 @@ type tracker struct {
 // - telemetryShortName: Short name for the plugin to use in telemetry. Used to avoid dot separated names like `com.company.pluginName`.
 // If a empty string is provided, it will use the pluginID.
 // - enableDiagnostics: Whether the system has enabled sending telemetry data. If false, the tracker will not track any event.
-// - l Logger: A logger to log any error related with the telemetry tracking.
+// It can be updated later with OnConfigurationChange.
+// - options: Options to filter and limit the events, e.g. WithDeniedProperties or WithDailyCap.
 func NewTracker(
 	c Client,
 	diagnosticID,
@@ -55,11 +139,12 @@ func NewTracker(
 	pluginVersion,
 	telemetryShortName string,
 	enableDiagnostics bool,
+	options ...TrackerOption,
 ) Tracker {
 	if telemetryShortName == "" {
 		telemetryShortName = pluginID
 	}
-	return &tracker{
+	t := &tracker{
 		telemetryShortName: telemetryShortName,
 		client:             c,
 		diagnosticID:       diagnosticID,
@@ -67,41 +152,176 @@ func NewTracker(
 		pluginID:           pluginID,
 		pluginVersion:      pluginVersion,
 		enabled:            enableDiagnostics,
+		random:             rand.Float64,
+		now:                time.Now,
 	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
 }
 
 func (t *tracker) TrackEvent(event string, properties map[string]interface{}) error {
+	return t.track(event, "", properties)
+}
+
+func (t *tracker) TrackUserEvent(event, userID string, properties map[string]interface{}) error {
+	return t.track(event, userID, properties)
+}
+
+func (t *tracker) OnConfigurationChange(config *model.Config) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.enabled = config != nil && config.LogSettings.EnableDiagnostics != nil && *config.LogSettings.EnableDiagnostics
+	if !t.enabled {
+		t.batch = nil
+		t.stopBatchTimer()
+	}
+}
+
+func (t *tracker) Flush() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.flush()
+}
+
+func (t *tracker) track(event, userID string, properties map[string]interface{}) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	if !t.enabled || t.client == nil {
 		return nil
 	}
 
-	if properties == nil {
-		properties = map[string]interface{}{}
+	if rate, ok := t.sampling[event]; ok && t.random() >= rate {
+		return nil
+	}
+
+	if !t.withinDailyCap() {
+		return nil
 	}
 
-	event = t.telemetryShortName + "_" + event
+	properties = t.scrub(properties)
+	if userID != "" {
+		properties["UserActualID"] = t.hash(userID)
+	}
 	properties["PluginID"] = t.pluginID
 	properties["PluginVersion"] = t.pluginVersion
 	properties["ServerVersion"] = t.serverVersion
 
-	err := t.client.Enqueue(Track{
+	track := Track{
 		UserID:     t.diagnosticID, // We consider the server the "user" on the telemetry system. Any reference to the actual user is passed by properties.
-		Event:      event,
+		Event:      t.telemetryShortName + "_" + event,
 		Properties: properties,
-	})
+	}
+
+	if t.batchSize <= 1 {
+		err := t.client.Enqueue(track)
+		if err != nil {
+			return errors.Wrap(err, "cannot enqueue the track")
+		}
+		return nil
+	}
 
-	if err != nil {
-		return errors.Wrap(err, "cannot enqueue the track")
+	if len(t.batch) == 0 {
+		t.batchStart = t.now()
+		t.batchTimer = time.AfterFunc(t.batchInterval, t.flushOnTimer)
+	}
+	t.batch = append(t.batch, track)
+	if len(t.batch) >= t.batchSize || t.now().Sub(t.batchStart) >= t.batchInterval {
+		return t.flush()
 	}
 
 	return nil
 }
 
-func (t *tracker) TrackUserEvent(event, userID string, properties map[string]interface{}) error {
-	if properties == nil {
-		properties = map[string]interface{}{}
+// flushOnTimer enqueues the events kept once the batch interval has passed, so that they are
+// sent even if no other event is tracked. There is no caller to return an error to, so the
+// events that cannot be enqueued are dropped.
+func (t *tracker) flushOnTimer() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	_ = t.flush()
+}
+
+func (t *tracker) stopBatchTimer() {
+	if t.batchTimer != nil {
+		t.batchTimer.Stop()
+		t.batchTimer = nil
 	}
+}
 
-	properties["UserActualID"] = userID
-	return t.TrackEvent(event, properties)
+func (t *tracker) flush() error {
+	t.stopBatchTimer()
+	batch := t.batch
+	t.batch = nil
+	for _, track := range batch {
+		err := t.client.Enqueue(track)
+		if err != nil {
+			return errors.Wrap(err, "cannot enqueue the track")
+		}
+	}
+
+	return nil
+}
+
+func (t *tracker) withinDailyCap() bool {
+	if t.dailyCap <= 0 {
+		return true
+	}
+
+	day := t.now().UTC().Format("2006-01-02")
+	if day != t.capDay {
+		t.capDay = day
+		t.capCount = 0
+	}
+
+	if t.capCount >= t.dailyCap {
+		return false
+	}
+
+	t.capCount++
+	return true
+}
+
+// scrub returns a copy of the properties without the ones filtered by the allow and deny lists,
+// and with the values looking like user IDs or emails hashed.
+func (t *tracker) scrub(properties map[string]interface{}) map[string]interface{} {
+	scrubbed := map[string]interface{}{}
+	for key, value := range properties {
+		if t.allowed != nil && !t.allowed[key] {
+			continue
+		}
+		if t.denied[key] {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			value = t.hash(s)
+		}
+		scrubbed[key] = value
+	}
+
+	return scrubbed
+}
+
+func (t *tracker) hash(value string) string {
+	if !t.hashing || !(model.IsValidId(value) || model.IsValidEmail(value)) {
+		return value
+	}
+
+	sum := sha256.Sum256([]byte(t.salt + value))
+	return hex.EncodeToString(sum[:])
+}
+
+func toSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
 }
@@ -0,0 +1,145 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracker(c Client, options ...TrackerOption) *tracker {
+	return NewTracker(c, "diagnostic-id", "5.30.0", "com.example.plugin", "1.0.0", "example", true, options...).(*tracker)
+}
+
+func TestTrackerProperties(t *testing.T) {
+	userID := model.NewId()
+
+	t.Run("allow list", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c, WithAllowedProperties("Step"))
+
+		require.NoError(t, tracker.TrackUserEvent("start", userID, map[string]interface{}{"Step": 1, "Message": "hello"}))
+		properties := c.Tracks()[0].Properties
+		assert.Equal(t, 1, properties["Step"])
+		assert.NotContains(t, properties, "Message")
+		assert.Equal(t, userID, properties["UserActualID"])
+		assert.Equal(t, "com.example.plugin", properties["PluginID"])
+	})
+
+	t.Run("deny list", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c, WithDeniedProperties("Message"))
+
+		properties := map[string]interface{}{"Step": 1, "Message": "hello"}
+		require.NoError(t, tracker.TrackEvent("start", properties))
+		assert.Equal(t, map[string]interface{}{
+			"Step":          1,
+			"PluginID":      "com.example.plugin",
+			"PluginVersion": "1.0.0",
+			"ServerVersion": "5.30.0",
+		}, c.Tracks()[0].Properties)
+		assert.Contains(t, properties, "Message")
+	})
+
+	t.Run("hashing", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c, WithHashing("salt"))
+
+		require.NoError(t, tracker.TrackUserEvent("start", userID, map[string]interface{}{
+			"Email": "jane@example.com",
+			"Step":  "intro",
+		}))
+		properties := c.Tracks()[0].Properties
+		assert.Len(t, properties["UserActualID"], 64)
+		assert.NotEqual(t, userID, properties["UserActualID"])
+		assert.Len(t, properties["Email"], 64)
+		assert.Equal(t, "intro", properties["Step"])
+
+		require.NoError(t, tracker.TrackUserEvent("stop", userID, nil))
+		assert.Equal(t, properties["UserActualID"], c.Tracks()[1].Properties["UserActualID"])
+	})
+}
+
+func TestTrackerLimits(t *testing.T) {
+	t.Run("sampling", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c, WithSampling(map[string]float64{"view": 0.5}))
+		values := []float64{0.2, 0.7}
+		tracker.random = func() float64 {
+			v := values[0]
+			values = values[1:]
+			return v
+		}
+
+		require.NoError(t, tracker.TrackEvent("view", nil))
+		require.NoError(t, tracker.TrackEvent("view", nil))
+		require.NoError(t, tracker.TrackEvent("start", nil))
+		assert.Equal(t, []string{"example_view", "example_start"}, c.Events())
+	})
+
+	t.Run("daily cap", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c, WithDailyCap(2))
+		now := time.Date(2021, 3, 4, 12, 0, 0, 0, time.UTC)
+		tracker.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, tracker.TrackEvent("start", nil))
+		}
+		assert.Len(t, c.Tracks(), 2)
+
+		now = now.AddDate(0, 0, 1)
+		require.NoError(t, tracker.TrackEvent("start", nil))
+		assert.Len(t, c.Tracks(), 3)
+	})
+
+	t.Run("batching", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c, WithBatching(3, time.Minute))
+		now := time.Date(2021, 3, 4, 12, 0, 0, 0, time.UTC)
+		tracker.now = func() time.Time { return now }
+
+		require.NoError(t, tracker.TrackEvent("one", nil))
+		require.NoError(t, tracker.TrackEvent("two", nil))
+		assert.Empty(t, c.Tracks())
+		require.NoError(t, tracker.TrackEvent("three", nil))
+		assert.Len(t, c.Tracks(), 3)
+
+		require.NoError(t, tracker.TrackEvent("four", nil))
+		now = now.Add(time.Minute)
+		require.NoError(t, tracker.TrackEvent("five", nil))
+		assert.Len(t, c.Tracks(), 5)
+
+		require.NoError(t, tracker.TrackEvent("six", nil))
+		require.NoError(t, tracker.Flush())
+		assert.Len(t, c.Tracks(), 6)
+	})
+
+	t.Run("batching flushes after the interval", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c, WithBatching(10, 10*time.Millisecond))
+
+		require.NoError(t, tracker.TrackEvent("one", nil))
+		assert.Empty(t, c.Tracks())
+		assert.Eventually(t, func() bool { return len(c.Tracks()) == 1 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("configuration change", func(t *testing.T) {
+		c := NewTestClient()
+		tracker := newTestTracker(c)
+		config := &model.Config{}
+		config.SetDefaults()
+
+		*config.LogSettings.EnableDiagnostics = false
+		tracker.OnConfigurationChange(config)
+		require.NoError(t, tracker.TrackEvent("start", nil))
+		assert.Empty(t, c.Tracks())
+
+		*config.LogSettings.EnableDiagnostics = true
+		tracker.OnConfigurationChange(config)
+		require.NoError(t, tracker.TrackEvent("start", nil))
+		assert.Len(t, c.Tracks(), 1)
+	})
+}
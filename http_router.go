@@ -0,0 +1,261 @@
+package pluginapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// HTTPError is an error answered with the given status code and message. Other errors returned
+// by handlers are answered with a 500 status code, and logged.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+// NewHTTPError creates a new HTTPError.
+func NewHTTPError(statusCode int, message string) *HTTPError {
+	return &HTTPError{
+		StatusCode: statusCode,
+		Message:    message,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
+}
+
+// HTTPContext holds the data of an authenticated request.
+type HTTPContext struct {
+	// UserID is the ID of the user making the request.
+	UserID string
+
+	client   *Client
+	userOnce sync.Once
+	user     *model.User
+	userErr  error
+}
+
+// User returns the user making the request. The user is fetched once per request.
+func (c *HTTPContext) User() (*model.User, error) {
+	c.userOnce.Do(func() {
+		c.user, c.userErr = c.client.User.Get(c.UserID)
+	})
+
+	return c.user, c.userErr
+}
+
+type httpContextKey struct{}
+
+// HTTPContextFromRequest returns the HTTPContext of a request served by the authenticated routes
+// of an HTTPRouter, or nil if there is none.
+func HTTPContextFromRequest(r *http.Request) *HTTPContext {
+	c, _ := r.Context().Value(httpContextKey{}).(*HTTPContext)
+	return c
+}
+
+// HTTPHandler handles an authenticated request. A returned error is answered with an error
+// response, see HTTPError.
+type HTTPHandler func(c *HTTPContext, w http.ResponseWriter, r *http.Request) error
+
+// ActionHandler handles an interactive message button or menu. A returned error is answered
+// with an error response, see HTTPError.
+type ActionHandler func(c *HTTPContext, request *model.PostActionIntegrationRequest) (*model.PostActionIntegrationResponse, error)
+
+// HTTPRouter serves the HTTP requests of a plugin. It requires the requests to be authenticated
+// with the Mattermost-User-ID header, recovers from panics and logs every request. Use it from
+// the ServeHTTP hook:
+//
+//     func (p *Plugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+//         p.router.ServeHTTP(w, r)
+//     }
+//
+// Handlers taking a *mux.Router, like the ones of the experimental packages, can be mounted on
+// Router or Public.
+type HTTPRouter struct {
+	client        *Client
+	root          *mux.Router
+	authenticated *mux.Router
+	public        *mux.Router
+}
+
+// NewHTTPRouter creates a new HTTPRouter.
+func NewHTTPRouter(client *Client) *HTTPRouter {
+	r := &HTTPRouter{
+		client: client,
+		root:   mux.NewRouter(),
+	}
+
+	r.authenticated = r.root.PathPrefix("/").Subrouter()
+	r.authenticated.Use(r.authenticate)
+	r.public = r.root.PathPrefix("/").Subrouter()
+
+	return r
+}
+
+// Router returns the router of the authenticated routes. Requests without the
+// Mattermost-User-ID header are answered with a 401 status code.
+func (r *HTTPRouter) Router() *mux.Router {
+	return r.authenticated
+}
+
+// Public returns the router of the routes that do not require authentication, e.g. webhooks.
+// Routes of Router take precedence over the ones of Public.
+func (r *HTTPRouter) Public() *mux.Router {
+	return r.public
+}
+
+// Handle registers an authenticated handler for the path.
+func (r *HTTPRouter) Handle(path string, handler HTTPHandler) *mux.Route {
+	return r.authenticated.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+		err := handler(HTTPContextFromRequest(req), w, req)
+		if err != nil {
+			r.writeError(w, req, err)
+		}
+	})
+}
+
+// Action registers the handler of the interactive message buttons and menus whose integration
+// URL is the path. Only JSON POST requests, as sent by the server, are accepted, so the action
+// cannot be triggered by a cross-site form or link. The user of the request must match the user
+// of the action.
+func (r *HTTPRouter) Action(path string, handler ActionHandler) *mux.Route {
+	return r.Handle(path, func(c *HTTPContext, w http.ResponseWriter, req *http.Request) error {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if mediaType != "application/json" {
+			return NewHTTPError(http.StatusUnsupportedMediaType, "expected a JSON request")
+		}
+
+		var request model.PostActionIntegrationRequest
+		err := DecodeJSON(req, &request)
+		if err != nil {
+			return err
+		}
+		if request.UserId != c.UserID {
+			return NewHTTPError(http.StatusForbidden, "the action does not belong to the user")
+		}
+
+		response, err := handler(c, &request)
+		if err != nil {
+			return err
+		}
+		if response == nil {
+			response = &model.PostActionIntegrationResponse{}
+		}
+
+		WriteJSON(w, http.StatusOK, response)
+		return nil
+	}).Methods(http.MethodPost)
+}
+
+// ServeHTTP implements http.Handler.
+func (r *HTTPRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	sw := &statusWriter{ResponseWriter: w}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r.client.Log.Error("Recovered from a panic in an HTTP handler",
+				"method", req.Method,
+				"path", req.URL.Path,
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			if sw.statusCode == 0 {
+				WriteJSONError(sw, http.StatusInternalServerError, "internal server error")
+			}
+		}
+
+		if sw.statusCode == 0 {
+			sw.statusCode = http.StatusOK
+		}
+		r.client.Log.Debug("Served HTTP request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", sw.statusCode,
+			"duration", time.Since(start).String(),
+			"user_id", req.Header.Get("Mattermost-User-ID"),
+		)
+	}()
+
+	r.root.ServeHTTP(sw, req)
+}
+
+func (r *HTTPRouter) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		userID := req.Header.Get("Mattermost-User-ID")
+		if userID == "" {
+			WriteJSONError(w, http.StatusUnauthorized, "not authorized")
+			return
+		}
+
+		c := &HTTPContext{
+			UserID: userID,
+			client: r.client,
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), httpContextKey{}, c)))
+	})
+}
+
+func (r *HTTPRouter) writeError(w http.ResponseWriter, req *http.Request, err error) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		WriteJSONError(w, httpErr.StatusCode, httpErr.Message)
+		return
+	}
+
+	r.client.Log.Error("HTTP handler failed", "method", req.Method, "path", req.URL.Path, "error", err.Error())
+	WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+}
+
+// DecodeJSON decodes the JSON body of the request into v. A decoding failure is returned as an
+// HTTPError with a 400 status code.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		return NewHTTPError(http.StatusBadRequest, "invalid JSON body")
+	}
+
+	return nil
+}
+
+// WriteJSON writes v as the JSON body of the response, with the given status code.
+func WriteJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// WriteJSONError writes an error response, whose JSON body holds the message in its error field.
+func WriteJSONError(w http.ResponseWriter, statusCode int, message string) {
+	WriteJSON(w, statusCode, map[string]string{"error": message})
+}
+
+// statusWriter records the status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,149 @@
+package pluginapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func anything(n int) []interface{} {
+	args := make([]interface{}, n)
+	for i := range args {
+		args[i] = mock.Anything
+	}
+	return args
+}
+
+func TestHTTPRouter(t *testing.T) {
+	userID := model.NewId()
+
+	api := &plugintest.API{}
+	api.On("LogDebug", anything(11)...).Return()
+	api.On("LogError", anything(7)...).Return()
+	api.On("LogError", anything(9)...).Return()
+	api.On("GetUser", userID).Return(&model.User{Id: userID, Username: "john"}, nil).Once()
+	client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+	router := pluginapi.NewHTTPRouter(client)
+	router.Handle("/me", func(c *pluginapi.HTTPContext, w http.ResponseWriter, r *http.Request) error {
+		user, err := c.User()
+		if err != nil {
+			return err
+		}
+		// The user is only fetched once.
+		_, _ = c.User()
+
+		pluginapi.WriteJSON(w, http.StatusOK, map[string]string{"username": user.Username})
+		return nil
+	}).Methods(http.MethodGet)
+	router.Handle("/echo", func(c *pluginapi.HTTPContext, w http.ResponseWriter, r *http.Request) error {
+		var body map[string]string
+		err := pluginapi.DecodeJSON(r, &body)
+		if err != nil {
+			return err
+		}
+
+		pluginapi.WriteJSON(w, http.StatusOK, body)
+		return nil
+	})
+	router.Handle("/forbidden", func(c *pluginapi.HTTPContext, w http.ResponseWriter, r *http.Request) error {
+		return pluginapi.NewHTTPError(http.StatusForbidden, "not yours")
+	})
+	router.Handle("/fail", func(c *pluginapi.HTTPContext, w http.ResponseWriter, r *http.Request) error {
+		return errors.New("database is down")
+	})
+	router.Handle("/panic", func(c *pluginapi.HTTPContext, w http.ResponseWriter, r *http.Request) error {
+		panic("oops")
+	})
+	router.Action("/action", func(c *pluginapi.HTTPContext, request *model.PostActionIntegrationRequest) (*model.PostActionIntegrationResponse, error) {
+		return &model.PostActionIntegrationResponse{EphemeralText: "clicked " + request.Context["button"].(string)}, nil
+	})
+	router.Router().HandleFunc("/mounted", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, userID, pluginapi.HTTPContextFromRequest(r).UserID)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	router.Public().HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, pluginapi.HTTPContextFromRequest(r))
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	serve := func(method, path, body, contentType string, authenticated bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if authenticated {
+			r.Header.Set("Mattermost-User-ID", userID)
+		}
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("authentication", func(t *testing.T) {
+		w := serve(http.MethodGet, "/me", "", "", false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error":"not authorized"}`, w.Body.String())
+
+		w = serve(http.MethodGet, "/me", "", "", true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"username":"john"}`, w.Body.String())
+
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodGet, "/mounted", "", "", true).Code)
+		assert.Equal(t, http.StatusAccepted, serve(http.MethodPost, "/webhook", "", "", false).Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/unknown", "", "", true).Code)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		w := serve(http.MethodPost, "/echo", `{"a":"b"}`, "application/json", true)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"a":"b"}`, w.Body.String())
+
+		w = serve(http.MethodPost, "/echo", `{`, "application/json", true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid JSON body"}`, w.Body.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w := serve(http.MethodGet, "/forbidden", "", "", true)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error":"not yours"}`, w.Body.String())
+
+		w = serve(http.MethodGet, "/fail", "", "", true)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"error":"internal server error"}`, w.Body.String())
+		api.AssertCalled(t, "LogError", "HTTP handler failed", "method", http.MethodGet, "path", "/fail", "error", "database is down")
+
+		w = serve(http.MethodGet, "/panic", "", "", true)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"error":"internal server error"}`, w.Body.String())
+		api.AssertCalled(t, "LogError", "Recovered from a panic in an HTTP handler", "method", http.MethodGet, "path", "/panic", "panic", "oops", "stack", mock.Anything)
+
+		api.AssertCalled(t, "LogDebug", "Served HTTP request", "method", http.MethodGet, "path", "/panic", "status", http.StatusInternalServerError, "duration", mock.Anything, "user_id", userID)
+	})
+
+	t.Run("action", func(t *testing.T) {
+		body := `{"user_id":"` + userID + `","context":{"button":"ok"}}`
+
+		w := serve(http.MethodPost, "/action", body, "application/json", true)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "clicked ok", model.PostActionIntegrationResponseFromJson(w.Body).EphemeralText)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/action", "", "", true).Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, serve(http.MethodPost, "/action", body, "application/x-www-form-urlencoded", true).Code)
+
+		otherUser := `{"user_id":"` + model.NewId() + `","context":{"button":"ok"}}`
+		assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/action", otherUser, "application/json", true).Code)
+	})
+}
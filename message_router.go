@@ -0,0 +1,236 @@
+package pluginapi
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// MessageContext describes a post matched by a MessageRouter route.
+type MessageContext struct {
+	// Post is the post that was matched.
+	Post *model.Post
+	// Channel is the channel the post was made in.
+	Channel *model.Channel
+	// Sender is the user that made the post.
+	Sender *model.User
+	// Captures holds the matched values. For a regular expression route, these are the
+	// submatches, the first being the entire match. For prefix and mention routes, the single
+	// capture is the remainder of the message, trimmed of surrounding whitespace.
+	Captures []string
+	// NamedCaptures holds the named submatches of a regular expression route.
+	NamedCaptures map[string]string
+}
+
+// MessageHandler handles a post matched by a MessageRouter route.
+type MessageHandler func(c *MessageContext) error
+
+// MessageRoute is a route registered with a MessageRouter.
+type MessageRoute struct {
+	match   func(r *MessageRouter, post *model.Post, options []ShouldProcessMessageOption) ([]string, map[string]string, error)
+	handler MessageHandler
+	options []ShouldProcessMessageOption
+}
+
+// ChannelTypes restricts the route to posts made in channels of the given types, e.g.
+// model.CHANNEL_DIRECT or model.CHANNEL_OPEN.
+//
+// By default, posts from all channel types are matched.
+func (route *MessageRoute) ChannelTypes(channelTypes ...string) *MessageRoute {
+	return route.Options(FilterChannelTypes(channelTypes))
+}
+
+// Options configures additional ShouldProcessMessage options for the route, on top of the
+// options given to the router.
+func (route *MessageRoute) Options(options ...ShouldProcessMessageOption) *MessageRoute {
+	route.options = append(route.options, options...)
+	return route
+}
+
+// MessageRouter dispatches posts to handlers registered by regular expression, by prefix or by
+// mention of the plugin bot. Use it from a MessageHasBeenPosted hook:
+//
+//     func (p *Plugin) MessageHasBeenPosted(c *plugin.Context, post *model.Post) {
+//         if _, err := p.router.Route(post); err != nil {
+//             p.client.Log.Warn("failed to route message", "error", err.Error())
+//         }
+//     }
+//
+// Routes are evaluated in the order they were registered, and only the first matching route is
+// handled.
+type MessageRouter struct {
+	client  *Client
+	options []ShouldProcessMessageOption
+	routes  []*MessageRoute
+
+	botMu        sync.Mutex
+	storedBotID  string
+	botUsernames map[string]string
+}
+
+// NewMessageRouter creates a new MessageRouter. The given options apply to all routes, and are
+// evaluated with ShouldProcessMessage before any handler is called.
+func NewMessageRouter(client *Client, options ...ShouldProcessMessageOption) *MessageRouter {
+	return &MessageRouter{
+		client:       client,
+		options:      options,
+		botUsernames: map[string]string{},
+	}
+}
+
+// Regexp registers a handler for posts whose message matches the given regular expression.
+func (r *MessageRouter) Regexp(re *regexp.Regexp, handler MessageHandler) *MessageRoute {
+	return r.addRoute(func(_ *MessageRouter, post *model.Post, _ []ShouldProcessMessageOption) ([]string, map[string]string, error) {
+		captures := re.FindStringSubmatch(post.Message)
+		if captures == nil {
+			return nil, nil, nil
+		}
+
+		namedCaptures := map[string]string{}
+		for i, name := range re.SubexpNames() {
+			if name != "" {
+				namedCaptures[name] = captures[i]
+			}
+		}
+
+		return captures, namedCaptures, nil
+	}, handler)
+}
+
+// Prefix registers a handler for posts whose message starts with the given prefix.
+func (r *MessageRouter) Prefix(prefix string, handler MessageHandler) *MessageRoute {
+	return r.addRoute(func(_ *MessageRouter, post *model.Post, _ []ShouldProcessMessageOption) ([]string, map[string]string, error) {
+		if !strings.HasPrefix(post.Message, prefix) {
+			return nil, nil, nil
+		}
+
+		return []string{strings.TrimSpace(strings.TrimPrefix(post.Message, prefix))}, nil, nil
+	}, handler)
+}
+
+// Mention registers a handler for posts mentioning the bot created by EnsureBot, or the bot
+// given with the BotID option, either to the router or to the route.
+func (r *MessageRouter) Mention(handler MessageHandler) *MessageRoute {
+	return r.addRoute(func(r *MessageRouter, post *model.Post, options []ShouldProcessMessageOption) ([]string, map[string]string, error) {
+		username, err := r.getBotUsername(options)
+		if err != nil {
+			return nil, nil, err
+		}
+		if username == "" {
+			return nil, nil, nil
+		}
+
+		loc := findMention(post.Message, username)
+		if loc == nil {
+			return nil, nil, nil
+		}
+
+		remainder := post.Message[:loc[0]] + post.Message[loc[1]:]
+
+		return []string{strings.TrimSpace(remainder)}, nil, nil
+	}, handler)
+}
+
+func (r *MessageRouter) addRoute(
+	match func(r *MessageRouter, post *model.Post, options []ShouldProcessMessageOption) ([]string, map[string]string, error),
+	handler MessageHandler,
+) *MessageRoute {
+	route := &MessageRoute{
+		match:   match,
+		handler: handler,
+	}
+	r.routes = append(r.routes, route)
+
+	return route
+}
+
+// Route dispatches the post to the first matching route, returning whether a handler was called
+// along with any error returned by the handler.
+func (r *MessageRouter) Route(post *model.Post) (bool, error) {
+	var channel *model.Channel
+
+	for _, route := range r.routes {
+		options := append(append([]ShouldProcessMessageOption{}, r.options...), route.options...)
+		captures, namedCaptures, err := route.match(r, post, options)
+		if err != nil {
+			return false, err
+		}
+		if captures == nil {
+			continue
+		}
+
+		shouldProcess, err := r.client.Post.ShouldProcessMessage(post, options...)
+		if err != nil {
+			return false, err
+		}
+		if !shouldProcess {
+			continue
+		}
+
+		if channel == nil {
+			channel, err = r.client.Channel.Get(post.ChannelId)
+			if err != nil {
+				return false, errors.Wrap(err, "unable to get channel")
+			}
+		}
+
+		sender, err := r.client.User.Get(post.UserId)
+		if err != nil {
+			return false, errors.Wrap(err, "unable to get user")
+		}
+
+		return true, route.handler(&MessageContext{
+			Post:          post,
+			Channel:       channel,
+			Sender:        sender,
+			Captures:      captures,
+			NamedCaptures: namedCaptures,
+		})
+	}
+
+	return false, nil
+}
+
+// getBotUsername returns the username of the bot given with the BotID option, or else of the
+// plugin bot, or an empty string if there is none.
+func (r *MessageRouter) getBotUsername(options []ShouldProcessMessageOption) (string, error) {
+	r.botMu.Lock()
+	defer r.botMu.Unlock()
+
+	messageProcessOptions := &shouldProcessMessageOptions{}
+	for _, option := range options {
+		option(messageProcessOptions)
+	}
+
+	botID := messageProcessOptions.BotID
+	if botID == "" {
+		if r.storedBotID == "" {
+			botIDBytes, appErr := r.client.api.KVGet(botUserKey)
+			if appErr != nil {
+				return "", errors.Wrap(appErr, "failed to get bot")
+			}
+			r.storedBotID = string(botIDBytes)
+		}
+		botID = r.storedBotID
+	}
+
+	if botID == "" {
+		return "", nil
+	}
+
+	if username, ok := r.botUsernames[botID]; ok {
+		return username, nil
+	}
+
+	bot, err := r.client.User.Get(botID)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to get bot user")
+	}
+
+	r.botUsernames[botID] = bot.Username
+
+	return bot.Username, nil
+}
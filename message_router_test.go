@@ -0,0 +1,199 @@
+package pluginapi_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestMessageRouter(t *testing.T) {
+	botID := model.NewId()
+	userID := model.NewId()
+	channelID := model.NewId()
+
+	setupAPI := func(channelType string) *plugintest.API {
+		api := &plugintest.API{}
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(botID), nil)
+		api.On("GetUser", userID).Return(&model.User{Id: userID, Username: "user"}, nil)
+		api.On("GetUser", botID).Return(&model.User{Id: botID, Username: "bot", IsBot: true}, nil)
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, Type: channelType}, nil)
+		return api
+	}
+
+	t.Run("regexp route", func(t *testing.T) {
+		api := setupAPI(model.CHANNEL_OPEN)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		var matched *pluginapi.MessageContext
+		router.Regexp(regexp.MustCompile(`^remind me in (?P<amount>\d+) (?P<unit>\w+)$`), func(c *pluginapi.MessageContext) error {
+			matched = c
+			return nil
+		})
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "remind me in 2 hours"})
+		require.NoError(t, err)
+		require.True(t, handled)
+		assert.Equal(t, []string{"remind me in 2 hours", "2", "hours"}, matched.Captures)
+		assert.Equal(t, map[string]string{"amount": "2", "unit": "hours"}, matched.NamedCaptures)
+		assert.Equal(t, channelID, matched.Channel.Id)
+		assert.Equal(t, userID, matched.Sender.Id)
+
+		handled, err = router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "remind me later"})
+		require.NoError(t, err)
+		assert.False(t, handled)
+	})
+
+	t.Run("prefix route", func(t *testing.T) {
+		api := setupAPI(model.CHANNEL_OPEN)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		var captures []string
+		router.Prefix("!todo", func(c *pluginapi.MessageContext) error {
+			captures = c.Captures
+			return nil
+		})
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "!todo  buy milk "})
+		require.NoError(t, err)
+		require.True(t, handled)
+		assert.Equal(t, []string{"buy milk"}, captures)
+	})
+
+	t.Run("mention route", func(t *testing.T) {
+		api := setupAPI(model.CHANNEL_OPEN)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		var captures []string
+		router.Mention(func(c *pluginapi.MessageContext) error {
+			captures = c.Captures
+			return nil
+		})
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "hey @bot, help"})
+		require.NoError(t, err)
+		require.True(t, handled)
+		assert.Equal(t, []string{"hey , help"}, captures)
+
+		handled, err = router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "hey @bottle"})
+		require.NoError(t, err)
+		assert.False(t, handled)
+	})
+
+	t.Run("mention route with a route bot", func(t *testing.T) {
+		otherBotID := model.NewId()
+		api := setupAPI(model.CHANNEL_OPEN)
+		api.On("GetUser", otherBotID).Return(&model.User{Id: otherBotID, Username: "other", IsBot: true}, nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		var mentioned []string
+		router.Mention(func(c *pluginapi.MessageContext) error {
+			mentioned = append(mentioned, "other")
+			return nil
+		}).Options(pluginapi.BotID(otherBotID))
+		router.Mention(func(c *pluginapi.MessageContext) error {
+			mentioned = append(mentioned, "bot")
+			return nil
+		})
+
+		for _, message := range []string{"hey @other", "hey @bot"} {
+			handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: message})
+			require.NoError(t, err)
+			require.True(t, handled)
+		}
+		assert.Equal(t, []string{"other", "bot"}, mentioned)
+	})
+
+	t.Run("mention route for plugin without a bot", func(t *testing.T) {
+		api := &plugintest.API{}
+		api.On("KVGet", plugin.BotUserKey).Return(nil, nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		router.Mention(func(c *pluginapi.MessageContext) error {
+			return nil
+		})
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "hey @bot"})
+		require.NoError(t, err)
+		assert.False(t, handled)
+	})
+
+	t.Run("channel type scoping", func(t *testing.T) {
+		api := setupAPI(model.CHANNEL_OPEN)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		var route string
+		router.Prefix("help", func(c *pluginapi.MessageContext) error {
+			route = "dm"
+			return nil
+		}).ChannelTypes(model.CHANNEL_DIRECT)
+		router.Prefix("help", func(c *pluginapi.MessageContext) error {
+			route = "public"
+			return nil
+		}).ChannelTypes(model.CHANNEL_OPEN, model.CHANNEL_PRIVATE)
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "help"})
+		require.NoError(t, err)
+		require.True(t, handled)
+		assert.Equal(t, "public", route)
+	})
+
+	t.Run("should process message options", func(t *testing.T) {
+		api := setupAPI(model.CHANNEL_OPEN)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client, pluginapi.FilterChannelIDs([]string{"another-channel-id"}))
+
+		router.Prefix("help", func(c *pluginapi.MessageContext) error {
+			return nil
+		})
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "help"})
+		require.NoError(t, err)
+		assert.False(t, handled)
+
+		handled, err = router.Route(&model.Post{UserId: botID, ChannelId: channelID, Message: "help"})
+		require.NoError(t, err)
+		assert.False(t, handled)
+	})
+
+	t.Run("route options", func(t *testing.T) {
+		api := setupAPI(model.CHANNEL_OPEN)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		router.Prefix("help", func(c *pluginapi.MessageContext) error {
+			return nil
+		}).Options(pluginapi.FilterUserIDs([]string{"another-user-id"}))
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "help"})
+		require.NoError(t, err)
+		assert.False(t, handled)
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		api := setupAPI(model.CHANNEL_OPEN)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+		router := pluginapi.NewMessageRouter(client)
+
+		router.Prefix("help", func(c *pluginapi.MessageContext) error {
+			return errors.New("handler error")
+		})
+
+		handled, err := router.Route(&model.Post{UserId: userID, ChannelId: channelID, Message: "help"})
+		require.EqualError(t, err, "handler error")
+		assert.True(t, handled)
+	})
+}
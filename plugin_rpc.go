@@ -0,0 +1,260 @@
+package pluginapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver/v4"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// RPCPath is the path, relative to the plugin, under which RPC methods are served.
+const RPCPath = "/rpc/"
+
+// Codes of the RPC errors returned by Call.
+const (
+	// RPCCodePluginNotRunning is returned when the called plugin is not installed or not running.
+	RPCCodePluginNotRunning = "plugin_not_running"
+	// RPCCodeIncompatibleVersion is returned when the called plugin is older than the version
+	// required with MinPluginVersion.
+	RPCCodeIncompatibleVersion = "incompatible_version"
+	// RPCCodeMethodNotFound is returned when the called plugin does not expose the method.
+	RPCCodeMethodNotFound = "method_not_found"
+	// RPCCodeForbidden is returned when the method is not called by a plugin.
+	RPCCodeForbidden = "forbidden"
+	// RPCCodeInvalidRequest is returned when the request cannot be decoded. Methods should
+	// return it with NewRPCError when the request is not valid.
+	RPCCodeInvalidRequest = "invalid_request"
+	// RPCCodeInternal is returned when the method fails with an error that is not an RPCError.
+	RPCCodeInternal = "internal_error"
+)
+
+// RPCError is the error of an RPC call. Methods return it with NewRPCError to give the caller a
+// code it can act upon, e.g. not_found.
+type RPCError struct {
+	// PluginID is the ID of the called plugin.
+	PluginID string `json:"-"`
+	// Method is the called method.
+	Method string `json:"-"`
+	// StatusCode is the HTTP status code of the response, or zero if the call was not made.
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+// NewRPCError creates a new RPCError to return from an RPCHandler.
+func NewRPCError(code, message string) *RPCError {
+	return &RPCError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *RPCError) Error() string {
+	if e.PluginID == "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("rpc %s.%s failed with %s: %s", e.PluginID, e.Method, e.Code, e.Message)
+}
+
+// CallOption configures a call to another plugin.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	minVersion string
+}
+
+// MinPluginVersion requires the called plugin to be at least at the given semantic version.
+func MinPluginVersion(version string) CallOption {
+	return func(o *callOptions) {
+		o.minVersion = version
+	}
+}
+
+// Call invokes an RPC method exposed by another plugin with an RPCServer. The request is sent as
+// JSON, and the JSON response is decoded into response, unless it is nil.
+//
+// Before calling, the status and the version of the plugin are checked. Failures are returned as
+// an *RPCError, whose Code tells what went wrong.
+//
+// The context is only checked before the call, since the server does not allow canceling an
+// inter-plugin request.
+//
+// Minimum server version: 5.18
+func (p *PluginService) Call(ctx context.Context, pluginID, method string, request, response interface{}, options ...CallOption) error {
+	o := callOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	err = p.checkPlugin(pluginID, method, o.minVersion)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/"+pluginID+RPCPath+method, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create the request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := p.HTTP(req)
+	if resp == nil {
+		return &RPCError{PluginID: pluginID, Method: method, Code: RPCCodeInternal, Message: "no response"}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the response")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		rpcErr := &RPCError{}
+		if json.Unmarshal(respBody, rpcErr) != nil || rpcErr.Code == "" {
+			// The plugin did not answer with an RPCServer, e.g. it does not expose RPC methods.
+			rpcErr.Code = RPCCodeInternal
+			if resp.StatusCode == http.StatusNotFound {
+				rpcErr.Code = RPCCodeMethodNotFound
+			}
+			rpcErr.Message = strings.TrimSpace(string(respBody))
+		}
+		rpcErr.PluginID = pluginID
+		rpcErr.Method = method
+		rpcErr.StatusCode = resp.StatusCode
+		return rpcErr
+	}
+
+	if response != nil {
+		err = json.Unmarshal(respBody, response)
+		if err != nil {
+			return errors.Wrap(err, "failed to unmarshal the response")
+		}
+	}
+
+	return nil
+}
+
+func (p *PluginService) checkPlugin(pluginID, method, minVersion string) error {
+	status, err := p.GetPluginStatus(pluginID)
+	if err != nil && err != ErrNotFound {
+		return errors.Wrapf(err, "failed to get the status of plugin %s", pluginID)
+	}
+	if status == nil || status.State != model.PluginStateRunning {
+		return &RPCError{PluginID: pluginID, Method: method, Code: RPCCodePluginNotRunning, Message: "the plugin is not running"}
+	}
+
+	if minVersion == "" {
+		return nil
+	}
+
+	required, err := semver.ParseTolerant(minVersion)
+	if err != nil {
+		return errors.Wrapf(err, "invalid minimum version %s", minVersion)
+	}
+	current, err := semver.ParseTolerant(status.Version)
+	if err != nil || current.LT(required) {
+		return &RPCError{
+			PluginID: pluginID,
+			Method:   method,
+			Code:     RPCCodeIncompatibleVersion,
+			Message:  fmt.Sprintf("version %s is running, %s is required", status.Version, minVersion),
+		}
+	}
+
+	return nil
+}
+
+// RPCHandler handles the calls to an RPC method. sourcePluginID is the ID of the calling plugin,
+// and request its JSON request. The response is sent back as JSON.
+type RPCHandler func(sourcePluginID string, request json.RawMessage) (response interface{}, err error)
+
+// RPCServer exposes RPC methods to other plugins, which call them with PluginService.Call. Serve
+// it under RPCPath from the ServeHTTP hook, or with an HTTPRouter:
+//
+//     router.Public().PathPrefix(pluginapi.RPCPath).Handler(rpcServer)
+//
+// Only inter-plugin requests are accepted.
+type RPCServer struct {
+	mu      sync.RWMutex
+	methods map[string]RPCHandler
+}
+
+// NewRPCServer creates a new RPCServer.
+func NewRPCServer() *RPCServer {
+	return &RPCServer{
+		methods: map[string]RPCHandler{},
+	}
+}
+
+// Register exposes a method. Registering a method again replaces its handler.
+func (s *RPCServer) Register(method string, handler RPCHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.methods[method] = handler
+}
+
+// ServeHTTP implements http.Handler.
+func (s *RPCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeRPCError(w, http.StatusMethodNotAllowed, NewRPCError(RPCCodeInvalidRequest, "method not allowed"))
+		return
+	}
+
+	sourcePluginID := r.Header.Get("Mattermost-Plugin-ID")
+	if sourcePluginID == "" {
+		writeRPCError(w, http.StatusForbidden, NewRPCError(RPCCodeForbidden, "only plugins can call RPC methods"))
+		return
+	}
+
+	method := strings.TrimPrefix(r.URL.Path, RPCPath)
+	s.mu.RLock()
+	handler, ok := s.methods[method]
+	s.mu.RUnlock()
+	if !ok || method == r.URL.Path {
+		writeRPCError(w, http.StatusNotFound, NewRPCError(RPCCodeMethodNotFound, fmt.Sprintf("method %s not found", method)))
+		return
+	}
+
+	var request json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeRPCError(w, http.StatusBadRequest, NewRPCError(RPCCodeInvalidRequest, "invalid JSON request"))
+		return
+	}
+
+	response, err := handler(sourcePluginID, request)
+	if err != nil {
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			writeRPCError(w, http.StatusBadRequest, rpcErr)
+			return
+		}
+		writeRPCError(w, http.StatusInternalServerError, NewRPCError(RPCCodeInternal, err.Error()))
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, response)
+}
+
+func writeRPCError(w http.ResponseWriter, statusCode int, err *RPCError) {
+	WriteJSON(w, statusCode, err)
+}
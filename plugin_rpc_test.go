@@ -0,0 +1,150 @@
+package pluginapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestPluginCall(t *testing.T) {
+	type greeting struct {
+		Name string `json:"name"`
+	}
+
+	server := pluginapi.NewRPCServer()
+	server.Register("greet", func(sourcePluginID string, request json.RawMessage) (interface{}, error) {
+		var g greeting
+		err := json.Unmarshal(request, &g)
+		if err != nil || g.Name == "" {
+			return nil, pluginapi.NewRPCError(pluginapi.RPCCodeInvalidRequest, "name is required")
+		}
+		return greeting{Name: "hello " + g.Name + " from " + sourcePluginID}, nil
+	})
+	server.Register("fail", func(sourcePluginID string, request json.RawMessage) (interface{}, error) {
+		return nil, errors.New("database is down")
+	})
+
+	setup := func(status *model.PluginStatus) *pluginapi.Client {
+		api := &plugintest.API{}
+		if status == nil {
+			api.On("GetPluginStatus", "target").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+		} else {
+			api.On("GetPluginStatus", "target").Return(status, nil)
+		}
+		api.On("PluginHTTP", mock.AnythingOfType("*http.Request")).Return(func(r *http.Request) *http.Response {
+			// Mimic the server, which strips the plugin ID and sets the source plugin.
+			require.True(t, strings.HasPrefix(r.URL.Path, "/target/"))
+			r.URL.Path = strings.TrimPrefix(r.URL.Path, "/target")
+			r.Header.Set("Mattermost-Plugin-ID", "source")
+
+			w := httptest.NewRecorder()
+			if strings.HasPrefix(r.URL.Path, pluginapi.RPCPath) {
+				server.ServeHTTP(w, r)
+			} else {
+				http.NotFound(w, r)
+			}
+			return w.Result()
+		})
+
+		return pluginapi.NewClient(api, &plugintest.Driver{})
+	}
+
+	running := &model.PluginStatus{PluginId: "target", State: model.PluginStateRunning, Version: "1.2.0"}
+
+	t.Run("call", func(t *testing.T) {
+		client := setup(running)
+
+		var resp greeting
+		err := client.Plugin.Call(context.Background(), "target", "greet", greeting{Name: "john"}, &resp, pluginapi.MinPluginVersion("1.1.0"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello john from source", resp.Name)
+	})
+
+	rpcError := func(t *testing.T, err error) *pluginapi.RPCError {
+		var rpcErr *pluginapi.RPCError
+		require.True(t, errors.As(err, &rpcErr), "expected an RPCError, got %v", err)
+		assert.Equal(t, "target", rpcErr.PluginID)
+		return rpcErr
+	}
+
+	t.Run("method errors", func(t *testing.T) {
+		client := setup(running)
+
+		err := client.Plugin.Call(context.Background(), "target", "greet", greeting{}, nil)
+		rpcErr := rpcError(t, err)
+		assert.Equal(t, pluginapi.RPCCodeInvalidRequest, rpcErr.Code)
+		assert.Equal(t, "name is required", rpcErr.Message)
+		assert.Equal(t, http.StatusBadRequest, rpcErr.StatusCode)
+		assert.Equal(t, "rpc target.greet failed with invalid_request: name is required", err.Error())
+
+		rpcErr = rpcError(t, client.Plugin.Call(context.Background(), "target", "fail", nil, nil))
+		assert.Equal(t, pluginapi.RPCCodeInternal, rpcErr.Code)
+		assert.Equal(t, "database is down", rpcErr.Message)
+
+		rpcErr = rpcError(t, client.Plugin.Call(context.Background(), "target", "unknown", nil, nil))
+		assert.Equal(t, pluginapi.RPCCodeMethodNotFound, rpcErr.Code)
+	})
+
+	t.Run("plugin checks", func(t *testing.T) {
+		rpcErr := rpcError(t, setup(nil).Plugin.Call(context.Background(), "target", "greet", nil, nil))
+		assert.Equal(t, pluginapi.RPCCodePluginNotRunning, rpcErr.Code)
+
+		stopped := &model.PluginStatus{PluginId: "target", State: model.PluginStateFailedToStart}
+		rpcErr = rpcError(t, setup(stopped).Plugin.Call(context.Background(), "target", "greet", nil, nil))
+		assert.Equal(t, pluginapi.RPCCodePluginNotRunning, rpcErr.Code)
+
+		rpcErr = rpcError(t, setup(running).Plugin.Call(context.Background(), "target", "greet", nil, nil, pluginapi.MinPluginVersion("v2.0.0")))
+		assert.Equal(t, pluginapi.RPCCodeIncompatibleVersion, rpcErr.Code)
+		assert.Equal(t, 0, rpcErr.StatusCode)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := setup(running).Plugin.Call(ctx, "target", "greet", nil, nil)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestRPCServer(t *testing.T) {
+	server := pluginapi.NewRPCServer()
+	server.Register("ping", func(sourcePluginID string, request json.RawMessage) (interface{}, error) {
+		return "pong", nil
+	})
+
+	t.Run("not a plugin", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/rpc/ping", strings.NewReader("null")))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"code":"forbidden","message":"only plugins can call RPC methods"}`, w.Body.String())
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/rpc/ping", strings.NewReader("{"))
+		r.Header.Set("Mattermost-Plugin-ID", "source")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("wrong method", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/rpc/ping", nil)
+		r.Header.Set("Mattermost-Plugin-ID", "source")
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
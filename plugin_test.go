@@ -1,10 +1,18 @@
 package pluginapi_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/mattermost/mattermost-server/v5/model"
@@ -12,6 +20,8 @@ import (
 	"github.com/mattermost/mattermost-server/v5/plugin/plugintest/mock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 )
@@ -98,6 +108,113 @@ func TestInstallPluginFromURL(t *testing.T) {
 	})
 }
 
+func TestInstallPluginFromURLOptions(t *testing.T) {
+	tarData, err := os.ReadFile(filepath.Join("tests", "testplugin.tar.gz"))
+	require.NoError(t, err)
+	sum := sha256.Sum256(tarData)
+	checksum := hex.EncodeToString(sum[:])
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Length", strconv.Itoa(len(tarData)))
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write(tarData)
+	}))
+	defer testServer.Close()
+
+	setup := func() (*plugintest.API, *pluginapi.Client) {
+		api := &plugintest.API{}
+		api.On("GetServerVersion").Return("5.19.0")
+		api.On("InstallPlugin", mock.Anything, false).Return(&model.Manifest{Id: "testplugin"}, nil)
+		return api, pluginapi.NewClient(api, &plugintest.Driver{})
+	}
+
+	t.Run("checksum", func(t *testing.T) {
+		_, client := setup()
+		manifest, err := client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallSHA256(strings.ToUpper(checksum)))
+		require.NoError(t, err)
+		assert.Equal(t, "testplugin", manifest.Id)
+
+		api, client := setup()
+		_, err = client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallSHA256("abc"))
+		assert.EqualError(t, err, "plugin checksum "+checksum+" does not match the expected checksum abc")
+		api.AssertNotCalled(t, "InstallPlugin", mock.Anything, mock.Anything)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		_, client := setup()
+		_, err := client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallMaxSize(int64(len(tarData))))
+		require.NoError(t, err)
+
+		api, client := setup()
+		_, err = client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallMaxSize(10))
+		assert.EqualError(t, err, fmt.Sprintf("plugin size %d exceeds the maximum size of 10 bytes", len(tarData)))
+		api.AssertNotCalled(t, "InstallPlugin", mock.Anything, mock.Anything)
+
+		// Without Content-Length, the size is checked while downloading.
+		chunkedServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			res.WriteHeader(http.StatusOK)
+			res.(http.Flusher).Flush()
+			_, _ = res.Write(tarData)
+		}))
+		defer chunkedServer.Close()
+		_, err = client.Plugin.InstallPluginFromURL(chunkedServer.URL, false, pluginapi.InstallMaxSize(10))
+		assert.EqualError(t, err, "plugin exceeds the maximum size of 10 bytes")
+	})
+
+	t.Run("signature", func(t *testing.T) {
+		entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+		require.NoError(t, err)
+		var publicKey bytes.Buffer
+		w, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+		require.NoError(t, err)
+		require.NoError(t, entity.Serialize(w))
+		require.NoError(t, w.Close())
+
+		var signature bytes.Buffer
+		require.NoError(t, openpgp.ArmoredDetachSign(&signature, entity, bytes.NewReader(tarData), nil))
+
+		_, client := setup()
+		_, err = client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallSignature(signature.Bytes(), publicKey.Bytes()))
+		require.NoError(t, err)
+
+		var otherSignature bytes.Buffer
+		require.NoError(t, openpgp.DetachSign(&otherSignature, entity, strings.NewReader("other"), nil))
+
+		api, client := setup()
+		_, err = client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallSignature(otherSignature.Bytes(), publicKey.Bytes()))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to verify the plugin signature")
+		api.AssertNotCalled(t, "InstallPlugin", mock.Anything, mock.Anything)
+	})
+
+	t.Run("context and client", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		api, client := setup()
+		_, err := client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallContext(ctx))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		api.AssertNotCalled(t, "InstallPlugin", mock.Anything, mock.Anything)
+
+		var requested bool
+		httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			requested = true
+			return http.DefaultTransport.RoundTrip(r)
+		})}
+		_, client = setup()
+		_, err = client.Plugin.InstallPluginFromURL(testServer.URL, false, pluginapi.InstallHTTPClient(httpClient))
+		require.NoError(t, err)
+		assert.True(t, requested)
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
 func TestGetPluginAssetURL(t *testing.T) {
 	siteURL := "https://mattermost.example.com"
 	api := &plugintest.API{}
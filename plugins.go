@@ -1,14 +1,22 @@
 package pluginapi
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
 )
 
 // PluginService exposes methods to manipulate the set of plugins as well as communicate with
@@ -36,22 +44,89 @@ func (p *PluginService) Install(file io.Reader, replace bool) (*model.Manifest,
 	return manifest, normalizeAppErr(appErr)
 }
 
+// InstallPluginOption configures InstallPluginFromURL.
+type InstallPluginOption func(*installPluginOptions)
+
+type installPluginOptions struct {
+	ctx       context.Context
+	client    *http.Client
+	sha256    string
+	signature []byte
+	publicKey []byte
+	maxSize   int64
+}
+
+// InstallContext sets the context of the download. Canceling it aborts the download, but not an
+// installation that has already started.
+func InstallContext(ctx context.Context) InstallPluginOption {
+	return func(o *installPluginOptions) {
+		o.ctx = ctx
+	}
+}
+
+// InstallHTTPClient sets the HTTP client used to download the plugin. It defaults to a client
+// with a one hour timeout.
+func InstallHTTPClient(client *http.Client) InstallPluginOption {
+	return func(o *installPluginOptions) {
+		o.client = client
+	}
+}
+
+// InstallSHA256 requires the downloaded plugin to have the given SHA-256 checksum, hex encoded.
+func InstallSHA256(checksum string) InstallPluginOption {
+	return func(o *installPluginOptions) {
+		o.sha256 = strings.ToLower(checksum)
+	}
+}
+
+// InstallSignature requires the downloaded plugin to be signed with the given detached OpenPGP
+// signature, made by the owner of the given public key. Both can be armored or binary, like the
+// plugin signatures verified by the server.
+func InstallSignature(signature, publicKey []byte) InstallPluginOption {
+	return func(o *installPluginOptions) {
+		o.signature = signature
+		o.publicKey = publicKey
+	}
+}
+
+// InstallMaxSize limits the size of the downloaded plugin, in bytes.
+func InstallMaxSize(maxSize int64) InstallPluginOption {
+	return func(o *installPluginOptions) {
+		o.maxSize = maxSize
+	}
+}
+
 // InstallPluginFromURL installs the plugin from the provided url.
 //
+// When a checksum, a signature or a maximum size is given, the plugin is fully downloaded and
+// verified before being installed.
+//
 // Minimum server version: 5.18
-func (p *PluginService) InstallPluginFromURL(downloadURL string, replace bool) (*model.Manifest, error) {
+func (p *PluginService) InstallPluginFromURL(downloadURL string, replace bool, options ...InstallPluginOption) (*model.Manifest, error) {
 	err := ensureServerVersion(p.api, "5.18.0")
 	if err != nil {
 		return nil, err
 	}
 
+	o := installPluginOptions{
+		ctx:    context.Background(),
+		client: &http.Client{Timeout: time.Hour},
+	}
+	for _, option := range options {
+		option(&o)
+	}
+
 	parsedURL, err := url.Parse(downloadURL)
 	if err != nil {
 		return nil, errors.Wrap(err, "error while parsing url")
 	}
 
-	client := &http.Client{Timeout: time.Hour}
-	response, err := client.Get(parsedURL.String())
+	request, err := http.NewRequestWithContext(o.ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the download request")
+	}
+
+	response, err := o.client.Do(request)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to download the plugin")
 	}
@@ -61,7 +136,28 @@ func (p *PluginService) InstallPluginFromURL(downloadURL string, replace bool) (
 		return nil, errors.Errorf("received %d status code while downloading plugin from server", response.StatusCode)
 	}
 
-	manifest, err := p.Install(response.Body, replace)
+	var body io.Reader = response.Body
+	if o.sha256 != "" || o.signature != nil || o.maxSize > 0 {
+		var data []byte
+		data, err = o.download(response)
+		if err != nil {
+			return nil, err
+		}
+
+		err = o.verify(data)
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	err = o.ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := p.Install(body, replace)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to install plugin on server")
 	}
@@ -69,6 +165,61 @@ func (p *PluginService) InstallPluginFromURL(downloadURL string, replace bool) (
 	return manifest, nil
 }
 
+func (o *installPluginOptions) download(response *http.Response) ([]byte, error) {
+	if o.maxSize <= 0 {
+		data, err := ioutil.ReadAll(response.Body)
+		return data, errors.Wrap(err, "unable to download the plugin")
+	}
+
+	if response.ContentLength > o.maxSize {
+		return nil, errors.Errorf("plugin size %d exceeds the maximum size of %d bytes", response.ContentLength, o.maxSize)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, o.maxSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to download the plugin")
+	}
+	if int64(len(data)) > o.maxSize {
+		return nil, errors.Errorf("plugin exceeds the maximum size of %d bytes", o.maxSize)
+	}
+
+	return data, nil
+}
+
+func (o *installPluginOptions) verify(data []byte) error {
+	if o.sha256 != "" {
+		sum := sha256.Sum256(data)
+		checksum := hex.EncodeToString(sum[:])
+		if checksum != o.sha256 {
+			return errors.Errorf("plugin checksum %s does not match the expected checksum %s", checksum, o.sha256)
+		}
+	}
+
+	if o.signature != nil {
+		keyring, err := openpgp.ReadKeyRing(decodeIfArmored(o.publicKey))
+		if err != nil {
+			return errors.Wrap(err, "unable to read the public key")
+		}
+
+		_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(data), decodeIfArmored(o.signature))
+		if err != nil {
+			return errors.Wrap(err, "unable to verify the plugin signature")
+		}
+	}
+
+	return nil
+}
+
+// decodeIfArmored returns the content of an armored OpenPGP block, or data as is if it is not
+// armored.
+func decodeIfArmored(data []byte) io.Reader {
+	block, err := armor.Decode(bytes.NewReader(data))
+	if err != nil {
+		return bytes.NewReader(data)
+	}
+	return block.Body
+}
+
 // Enable will enable an plugin installed.
 //
 // Minimum server version: 5.6
@@ -1,6 +1,9 @@
 package pluginapi
 
 import (
+	"regexp"
+	"sort"
+
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
 	"github.com/pkg/errors"
@@ -181,6 +184,150 @@ func (p *PostService) RemoveReaction(reaction *model.Reaction) error {
 	return normalizeAppErr(p.api.RemoveReaction(reaction))
 }
 
+// Reply creates a post as a reply in the thread of the given post.
+//
+// The given ID may refer to either the root post of a thread or any reply within it: the
+// reply is always attached to the root of the thread. If unset, the post's channel is
+// taken from the thread.
+//
+// Minimum server version: 5.2
+func (p *PostService) Reply(rootID string, post *model.Post) error {
+	root, err := p.GetPost(rootID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get thread root")
+	}
+
+	post.RootId = root.Id
+	if root.RootId != "" {
+		post.RootId = root.RootId
+	}
+	post.ParentId = ""
+	if post.ChannelId == "" {
+		post.ChannelId = root.ChannelId
+	}
+
+	return p.CreatePost(post)
+}
+
+// ThreadIterator walks the posts of a thread from oldest to newest.
+//
+// Use it as follows:
+//
+//     it, err := client.Post.GetThreadIterator(postID)
+//     if err != nil {
+//         return err
+//     }
+//     for it.Next() {
+//         post := it.Post()
+//         ...
+//     }
+type ThreadIterator struct {
+	posts []*model.Post
+	index int
+}
+
+// NewThreadIterator creates an iterator over the given post list, ordered by creation time.
+//
+// The post list itself is not modified.
+func NewThreadIterator(postList *model.PostList) *ThreadIterator {
+	posts := make([]*model.Post, 0, len(postList.Posts))
+	for _, post := range postList.Posts {
+		posts = append(posts, post)
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		if posts[i].CreateAt == posts[j].CreateAt {
+			return posts[i].Id < posts[j].Id
+		}
+		return posts[i].CreateAt < posts[j].CreateAt
+	})
+
+	return &ThreadIterator{
+		posts: posts,
+		index: -1,
+	}
+}
+
+// Next advances the iterator to the next post, returning false once the thread is exhausted.
+func (it *ThreadIterator) Next() bool {
+	if it.index < len(it.posts) {
+		it.index++
+	}
+
+	return it.index < len(it.posts)
+}
+
+// Post returns the current post, or nil if Next has not been called or returned false.
+func (it *ThreadIterator) Post() *model.Post {
+	if it.index < 0 || it.index >= len(it.posts) {
+		return nil
+	}
+
+	return it.posts[it.index]
+}
+
+// Len returns the total number of posts in the thread.
+func (it *ThreadIterator) Len() int {
+	return len(it.posts)
+}
+
+// GetThreadIterator gets the thread containing the given post and returns an iterator over its
+// posts, ordered from oldest to newest.
+//
+// Minimum server version: 5.6
+func (p *PostService) GetThreadIterator(postID string) (*ThreadIterator, error) {
+	postList, err := p.GetPostThread(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewThreadIterator(postList), nil
+}
+
+// GetLastThreadPostByUser returns the most recent post made by the given user in the thread
+// containing the given post.
+//
+// Returns ErrNotFound if the user has not posted in the thread.
+//
+// Minimum server version: 5.6
+func (p *PostService) GetLastThreadPostByUser(postID, userID string) (*model.Post, error) {
+	it, err := p.GetThreadIterator(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	var last *model.Post
+	for it.Next() {
+		if post := it.Post(); post.UserId == userID && post.DeleteAt == 0 {
+			last = post
+		}
+	}
+
+	if last == nil {
+		return nil, ErrNotFound
+	}
+
+	return last, nil
+}
+
+// GetLastThreadPostByBot returns the most recent post made by the bot created by EnsureBot in
+// the thread containing the given post.
+//
+// Returns ErrNotFound if the plugin has no bot, or if the bot has not posted in the thread.
+//
+// Minimum server version: 5.6
+func (p *PostService) GetLastThreadPostByBot(postID string) (*model.Post, error) {
+	botIDBytes, appErr := p.api.KVGet(botUserKey)
+	if appErr != nil {
+		return nil, errors.Wrap(appErr, "failed to get bot")
+	}
+	if botIDBytes == nil {
+		return nil, ErrNotFound
+	}
+
+	return p.GetLastThreadPostByUser(postID, string(botIDBytes))
+}
+
 type ShouldProcessMessageOption func(*shouldProcessMessageOptions)
 
 type shouldProcessMessageOptions struct {
@@ -191,6 +338,11 @@ type shouldProcessMessageOptions struct {
 	FilterUserIDs       []string
 	OnlyBotDMs          bool
 	BotID               string
+	FilterTeamIDs       []string
+	FilterChannelTypes  []string
+	OnlyBotMentions     bool
+	OnlyThreadReplies   bool
+	IgnoreBotIDs        []string
 }
 
 // AllowSystemMessages configures a call to ShouldProcessMessage to return true for system messages.
@@ -257,6 +409,56 @@ func BotID(botID string) ShouldProcessMessageOption {
 	}
 }
 
+// FilterTeamIDs configures a call to ShouldProcessMessage to return true only for channels in the given teams.
+//
+// As direct and group messages do not belong to a team, they are never processed when this option is set.
+// By default, posts from all teams are allowed to be processed.
+func FilterTeamIDs(filterTeamIDs []string) ShouldProcessMessageOption {
+	return func(options *shouldProcessMessageOptions) {
+		options.FilterTeamIDs = filterTeamIDs
+	}
+}
+
+// FilterChannelTypes configures a call to ShouldProcessMessage to return true only for channels of the given types,
+// e.g. model.CHANNEL_OPEN, model.CHANNEL_PRIVATE, model.CHANNEL_DIRECT or model.CHANNEL_GROUP.
+//
+// By default, posts from all channel types are allowed to be processed.
+func FilterChannelTypes(filterChannelTypes []string) ShouldProcessMessageOption {
+	return func(options *shouldProcessMessageOptions) {
+		options.FilterChannelTypes = filterChannelTypes
+	}
+}
+
+// OnlyBotMentions configures a call to ShouldProcessMessage to return true only for messages mentioning the bot
+// created by EnsureBot, or the bot given with BotID.
+//
+// By default, posts are allowed regardless of their mentions.
+func OnlyBotMentions() ShouldProcessMessageOption {
+	return func(options *shouldProcessMessageOptions) {
+		options.OnlyBotMentions = true
+	}
+}
+
+// OnlyThreadReplies configures a call to ShouldProcessMessage to return true only for replies within a thread.
+//
+// By default, both root posts and replies are allowed.
+func OnlyThreadReplies() ShouldProcessMessageOption {
+	return func(options *shouldProcessMessageOptions) {
+		options.OnlyThreadReplies = true
+	}
+}
+
+// IgnoreBotIDs configures a call to ShouldProcessMessage to return false for posts from the given bots, even
+// when AllowBots is set.
+//
+// Use this option in plugins managing multiple bots to avoid responding to their own posts. The bot created by
+// EnsureBot, or given with BotID, is always ignored.
+func IgnoreBotIDs(botIDs []string) ShouldProcessMessageOption {
+	return func(options *shouldProcessMessageOptions) {
+		options.IgnoreBotIDs = botIDs
+	}
+}
+
 // ShouldProcessMessage returns if the message should be processed by a message hook.
 //
 // Use this method to avoid processing unnecessary messages in a MessageHasBeenPosted
@@ -319,10 +521,55 @@ func (p *PostService) ShouldProcessMessage(post *model.Post, options ...ShouldPr
 		return false, nil
 	}
 
-	if botIDBytes != nil && messageProcessOptions.OnlyBotDMs {
-		channel, appErr := p.api.GetChannel(post.ChannelId)
+	if messageProcessOptions.OnlyThreadReplies && post.RootId == "" {
+		return false, nil
+	}
+
+	if len(messageProcessOptions.IgnoreBotIDs) != 0 && stringInSlice(post.UserId, messageProcessOptions.IgnoreBotIDs) {
+		return false, nil
+	}
+
+	var channel *model.Channel
+	getChannel := func() (*model.Channel, error) {
+		if channel != nil {
+			return channel, nil
+		}
+
+		var appErr *model.AppError
+		channel, appErr = p.api.GetChannel(post.ChannelId)
 		if appErr != nil {
-			return false, errors.Wrap(appErr, "unable to get channel")
+			return nil, errors.Wrap(appErr, "unable to get channel")
+		}
+
+		return channel, nil
+	}
+
+	if len(messageProcessOptions.FilterChannelTypes) != 0 {
+		channel, err := getChannel()
+		if err != nil {
+			return false, err
+		}
+
+		if !stringInSlice(channel.Type, messageProcessOptions.FilterChannelTypes) {
+			return false, nil
+		}
+	}
+
+	if len(messageProcessOptions.FilterTeamIDs) != 0 {
+		channel, err := getChannel()
+		if err != nil {
+			return false, err
+		}
+
+		if !stringInSlice(channel.TeamId, messageProcessOptions.FilterTeamIDs) {
+			return false, nil
+		}
+	}
+
+	if botIDBytes != nil && messageProcessOptions.OnlyBotDMs {
+		channel, err := getChannel()
+		if err != nil {
+			return false, err
 		}
 
 		if !model.IsBotDMChannel(channel, string(botIDBytes)) {
@@ -330,5 +577,36 @@ func (p *PostService) ShouldProcessMessage(post *model.Post, options ...ShouldPr
 		}
 	}
 
+	if messageProcessOptions.OnlyBotMentions {
+		if botIDBytes == nil {
+			return false, nil
+		}
+
+		bot, appErr := p.api.GetUser(string(botIDBytes))
+		if appErr != nil {
+			return false, errors.Wrap(appErr, "unable to get bot user")
+		}
+
+		if findMention(post.Message, bot.Username) == nil {
+			return false, nil
+		}
+	}
+
 	return true, nil
 }
+
+// findMention returns the start and end index of the first @-mention of the given username in
+// the message, or nil if the username is not mentioned.
+func findMention(message, username string) []int {
+	if username == "" {
+		return nil
+	}
+
+	mention := regexp.MustCompile(`(?i)(?:^|\W)(@` + regexp.QuoteMeta(username) + `)[.\-]*(?:$|[^\w.\-])`)
+	loc := mention.FindStringSubmatchIndex(message)
+	if loc == nil {
+		return nil
+	}
+
+	return loc[2:4]
+}
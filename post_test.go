@@ -738,4 +738,329 @@ func TestShouldProcessMessage(t *testing.T) {
 
 		assert.True(t, shouldProcessMessage)
 	})
+
+	t.Run("should not process the message as the post is in another team", func(t *testing.T) {
+		channelID := "1"
+		api := setupAPI()
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, TeamId: "team-id", Type: model.CHANNEL_OPEN}, nil)
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{ChannelId: channelID},
+			pluginapi.AllowBots(),
+			pluginapi.FilterTeamIDs([]string{"another-team-id"}),
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, shouldProcessMessage)
+	})
+
+	t.Run("should not process the message as the post is in a direct channel with a team filter", func(t *testing.T) {
+		channelID := "1"
+		api := setupAPI()
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, Type: model.CHANNEL_DIRECT}, nil)
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{ChannelId: channelID},
+			pluginapi.AllowBots(),
+			pluginapi.FilterTeamIDs([]string{"team-id"}),
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, shouldProcessMessage)
+	})
+
+	t.Run("should process the message as the post is in the filtered team", func(t *testing.T) {
+		channelID := "1"
+		api := setupAPI()
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, TeamId: "team-id", Type: model.CHANNEL_OPEN}, nil).Once()
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{ChannelId: channelID},
+			pluginapi.AllowBots(),
+			pluginapi.FilterTeamIDs([]string{"team-id"}),
+			pluginapi.FilterChannelTypes([]string{model.CHANNEL_OPEN}),
+		)
+
+		assert.NoError(t, err)
+		assert.True(t, shouldProcessMessage)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("should not process the message as the channel type is filtered", func(t *testing.T) {
+		channelID := "1"
+		api := setupAPI()
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, Type: model.CHANNEL_PRIVATE}, nil)
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{ChannelId: channelID},
+			pluginapi.AllowBots(),
+			pluginapi.FilterChannelTypes([]string{model.CHANNEL_DIRECT, model.CHANNEL_GROUP}),
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, shouldProcessMessage)
+	})
+
+	t.Run("should process the message as the channel type is allowed", func(t *testing.T) {
+		channelID := "1"
+		api := setupAPI()
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, Type: model.CHANNEL_GROUP}, nil)
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{ChannelId: channelID},
+			pluginapi.AllowBots(),
+			pluginapi.FilterChannelTypes([]string{model.CHANNEL_DIRECT, model.CHANNEL_GROUP}),
+		)
+
+		assert.NoError(t, err)
+		assert.True(t, shouldProcessMessage)
+	})
+
+	t.Run("should not process the message as the bot is not mentioned", func(t *testing.T) {
+		api := setupAPI()
+		api.On("GetUser", expectedBotID).Return(&model.User{Id: expectedBotID, Username: "bot"}, nil)
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{Message: "hello @bottle and bot"},
+			pluginapi.AllowBots(),
+			pluginapi.OnlyBotMentions(),
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, shouldProcessMessage)
+	})
+
+	t.Run("should process the message as the bot is mentioned", func(t *testing.T) {
+		api := setupAPI()
+		api.On("GetUser", expectedBotID).Return(&model.User{Id: expectedBotID, Username: "bot"}, nil)
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{Message: "hello @Bot."},
+			pluginapi.AllowBots(),
+			pluginapi.OnlyBotMentions(),
+		)
+
+		assert.NoError(t, err)
+		assert.True(t, shouldProcessMessage)
+	})
+
+	t.Run("should not process the message mentioning the bot for plugin without a bot", func(t *testing.T) {
+		api := setupAPI()
+		api.On("KVGet", plugin.BotUserKey).Return(nil, nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{Message: "hello @bot"},
+			pluginapi.AllowBots(),
+			pluginapi.OnlyBotMentions(),
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, shouldProcessMessage)
+	})
+
+	t.Run("should not process the message as the post is not a thread reply", func(t *testing.T) {
+		api := setupAPI()
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{},
+			pluginapi.AllowBots(),
+			pluginapi.OnlyThreadReplies(),
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, shouldProcessMessage)
+	})
+
+	t.Run("should process the message as the post is a thread reply", func(t *testing.T) {
+		api := setupAPI()
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{RootId: model.NewId()},
+			pluginapi.AllowBots(),
+			pluginapi.OnlyThreadReplies(),
+		)
+
+		assert.NoError(t, err)
+		assert.True(t, shouldProcessMessage)
+	})
+
+	t.Run("should not process the message as the post is created by another plugin bot", func(t *testing.T) {
+		otherBotID := model.NewId()
+		api := setupAPI()
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(expectedBotID), nil)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		shouldProcessMessage, err := client.Post.ShouldProcessMessage(
+			&model.Post{UserId: otherBotID},
+			pluginapi.AllowBots(),
+			pluginapi.IgnoreBotIDs([]string{otherBotID}),
+		)
+
+		assert.NoError(t, err)
+		assert.False(t, shouldProcessMessage)
+	})
+}
+
+func TestReply(t *testing.T) {
+	t.Run("reply to root post", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		rootID := model.NewId()
+		channelID := model.NewId()
+		api.On("GetPost", rootID).Return(&model.Post{Id: rootID, ChannelId: channelID}, nil)
+
+		expected := &model.Post{RootId: rootID, ChannelId: channelID, Message: "reply"}
+		api.On("CreatePost", expected).Return(expected, nil)
+
+		post := &model.Post{Message: "reply"}
+		err := client.Post.Reply(rootID, post)
+		require.NoError(t, err)
+		assert.Equal(t, rootID, post.RootId)
+		assert.Equal(t, channelID, post.ChannelId)
+	})
+
+	t.Run("reply to a reply", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		rootID := model.NewId()
+		replyID := model.NewId()
+		channelID := model.NewId()
+		api.On("GetPost", replyID).Return(&model.Post{Id: replyID, RootId: rootID, ChannelId: channelID}, nil)
+
+		expected := &model.Post{RootId: rootID, ChannelId: channelID, Message: "reply"}
+		api.On("CreatePost", expected).Return(expected, nil)
+
+		post := &model.Post{Message: "reply"}
+		err := client.Post.Reply(replyID, post)
+		require.NoError(t, err)
+		assert.Equal(t, rootID, post.RootId)
+	})
+
+	t.Run("failure to get root", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		rootID := model.NewId()
+		api.On("GetPost", rootID).Return(nil, newAppError())
+
+		err := client.Post.Reply(rootID, &model.Post{Message: "reply"})
+		require.EqualError(t, err, "failed to get thread root: here: id, an error occurred")
+	})
+}
+
+func TestThreadIterator(t *testing.T) {
+	t.Run("ordered by creation time", func(t *testing.T) {
+		postList := model.NewPostList()
+		postList.AddPost(&model.Post{Id: "c", CreateAt: 3})
+		postList.AddPost(&model.Post{Id: "a", CreateAt: 1})
+		postList.AddPost(&model.Post{Id: "b", CreateAt: 2})
+		postList.AddOrder("c")
+		postList.AddOrder("a")
+		postList.AddOrder("b")
+
+		it := pluginapi.NewThreadIterator(postList)
+		assert.Nil(t, it.Post())
+		assert.Equal(t, 3, it.Len())
+
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Post().Id)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, ids)
+		assert.False(t, it.Next())
+		assert.Nil(t, it.Post())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		it := pluginapi.NewThreadIterator(model.NewPostList())
+		assert.False(t, it.Next())
+		assert.Nil(t, it.Post())
+	})
+}
+
+func TestGetLastThreadPostByUser(t *testing.T) {
+	postID := "postID"
+	userID := "userID"
+
+	newThread := func() *model.PostList {
+		postList := model.NewPostList()
+		postList.AddPost(&model.Post{Id: postID, UserId: "other", CreateAt: 1})
+		postList.AddPost(&model.Post{Id: "reply1", RootId: postID, UserId: userID, CreateAt: 2})
+		postList.AddPost(&model.Post{Id: "reply2", RootId: postID, UserId: userID, CreateAt: 3})
+		postList.AddPost(&model.Post{Id: "reply3", RootId: postID, UserId: "other", CreateAt: 4})
+		return postList
+	}
+
+	t.Run("found", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		api.On("GetPostThread", postID).Return(newThread(), nil)
+
+		post, err := client.Post.GetLastThreadPostByUser(postID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "reply2", post.Id)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		api.On("GetPostThread", postID).Return(newThread(), nil)
+
+		post, err := client.Post.GetLastThreadPostByUser(postID, "unknown")
+		require.Equal(t, pluginapi.ErrNotFound, err)
+		assert.Nil(t, post)
+	})
+
+	t.Run("by bot", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		api.On("KVGet", plugin.BotUserKey).Return([]byte(userID), nil)
+		api.On("GetPostThread", postID).Return(newThread(), nil)
+
+		post, err := client.Post.GetLastThreadPostByBot(postID)
+		require.NoError(t, err)
+		assert.Equal(t, "reply2", post.Id)
+	})
+
+	t.Run("plugin without a bot", func(t *testing.T) {
+		api := &plugintest.API{}
+		defer api.AssertExpectations(t)
+		client := pluginapi.NewClient(api, &plugintest.Driver{})
+
+		api.On("KVGet", plugin.BotUserKey).Return(nil, nil)
+
+		post, err := client.Post.GetLastThreadPostByBot(postID)
+		require.Equal(t, pluginapi.ErrNotFound, err)
+		assert.Nil(t, post)
+	})
 }
@@ -0,0 +1,251 @@
+package pluginapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// LicenseRequirement is the license a plugin requires. Servers configured for development meet
+// every license requirement, see IsConfiguredForDevelopment.
+type LicenseRequirement int
+
+const (
+	// LicenseNone requires no license.
+	LicenseNone LicenseRequirement = iota
+	// LicenseAny requires any license, see IsEnterpriseLicensedOrDevelopment.
+	LicenseAny
+	// LicenseE10 requires a Professional or E10 license, or higher, see IsE10LicensedOrDevelopment.
+	LicenseE10
+	// LicenseE20 requires an Enterprise or E20 license, see IsE20LicensedOrDevelopment.
+	LicenseE20
+)
+
+// PluginRequirement describes a plugin another plugin depends on.
+type PluginRequirement struct {
+	// ID is the ID of the plugin, e.g. jira.
+	ID string
+	// MinVersion is the minimum semantic version of the plugin, if any.
+	MinVersion string
+	// Optional dependencies are reported, but do not fail the check.
+	Optional bool
+}
+
+// Requirements are the conditions the server must meet for a plugin to work.
+type Requirements struct {
+	// MinServerVersion is the minimum semantic version of the server, if any.
+	MinServerVersion string
+	License          LicenseRequirement
+	// Configuration holds the settings the server must have, see
+	// ConfigurationService.CheckRequiredServerConfiguration.
+	Configuration *model.Config
+	Plugins       []PluginRequirement
+}
+
+// RequirementCheck is the result of the check of a single requirement.
+type RequirementCheck struct {
+	// Name describes the requirement, e.g. "Server version" or "Plugin jira".
+	Name string
+	OK   bool
+	// Message explains why the requirement is not met, or describes what was found.
+	Message  string
+	Optional bool
+}
+
+// RequirementsReport is the result of CheckRequirements.
+type RequirementsReport struct {
+	Checks []RequirementCheck
+}
+
+// OK returns true if all requirements that are not optional are met.
+func (r *RequirementsReport) OK() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures returns the checks of the requirements that are not met, excluding optional ones.
+func (r *RequirementsReport) Failures() []RequirementCheck {
+	failures := []RequirementCheck{}
+	for _, check := range r.Checks {
+		if !check.OK && !check.Optional {
+			failures = append(failures, check)
+		}
+	}
+
+	return failures
+}
+
+// Err returns an error listing the requirements that are not met, or nil if OK. It is meant to
+// be returned from OnActivate.
+func (r *RequirementsReport) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	messages := []string{}
+	for _, check := range failures {
+		messages = append(messages, strings.ToLower(check.Name)+": "+check.Message)
+	}
+
+	return errors.Errorf("requirements not met: %s", strings.Join(messages, "; "))
+}
+
+// String returns the report as a Markdown list, to be shown to system admins.
+func (r *RequirementsReport) String() string {
+	lines := []string{}
+	for _, check := range r.Checks {
+		status := "OK"
+		switch {
+		case !check.OK && check.Optional:
+			status = "Warning"
+		case !check.OK:
+			status = "Failed"
+		}
+
+		line := fmt.Sprintf("- **%s**: %s", check.Name, status)
+		if check.Message != "" {
+			line += " (" + check.Message + ")"
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// CheckRequirements checks the server version, the license, the configuration and the plugins
+// the plugin depends on. The report lists every requirement, met or not; an error is only
+// returned if the checks cannot be made, e.g. if a requirement is malformed.
+//
+// Minimum server version: 5.6
+func (p *PluginService) CheckRequirements(requirements Requirements) (*RequirementsReport, error) {
+	report := &RequirementsReport{}
+
+	if requirements.MinServerVersion != "" {
+		check, err := checkVersion("Server version", p.api.GetServerVersion(), requirements.MinServerVersion)
+		if err != nil {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	config := p.api.GetConfig()
+	if requirements.License != LicenseNone {
+		report.Checks = append(report.Checks, checkLicense(requirements.License, config, p.api.GetLicense()))
+	}
+
+	if requirements.Configuration != nil {
+		configuration := ConfigurationService{api: p.api}
+		ok, err := configuration.CheckRequiredServerConfiguration(requirements.Configuration)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check the server configuration")
+		}
+
+		check := RequirementCheck{Name: "Server configuration", OK: ok}
+		if !ok {
+			check.Message = "the server is not configured as required"
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	for _, plugin := range requirements.Plugins {
+		check, err := p.checkPluginRequirement(plugin, config)
+		if err != nil {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	return report, nil
+}
+
+func (p *PluginService) checkPluginRequirement(requirement PluginRequirement, config *model.Config) (RequirementCheck, error) {
+	check := RequirementCheck{
+		Name:     "Plugin " + requirement.ID,
+		Optional: requirement.Optional,
+	}
+
+	status, err := p.GetPluginStatus(requirement.ID)
+	if err == ErrNotFound {
+		check.Message = "not installed"
+		return check, nil
+	}
+	if err != nil {
+		return check, errors.Wrapf(err, "failed to get the status of plugin %s", requirement.ID)
+	}
+
+	if config != nil {
+		state := config.PluginSettings.PluginStates[requirement.ID]
+		if state == nil || !state.Enable {
+			check.Message = "installed but disabled"
+			return check, nil
+		}
+	}
+
+	if status.State != model.PluginStateRunning {
+		check.Message = "enabled but not running"
+		return check, nil
+	}
+
+	if requirement.MinVersion == "" {
+		check.OK = true
+		check.Message = "version " + status.Version
+		return check, nil
+	}
+
+	versionCheck, err := checkVersion(check.Name, status.Version, requirement.MinVersion)
+	if err != nil {
+		return check, err
+	}
+	versionCheck.Optional = requirement.Optional
+
+	return versionCheck, nil
+}
+
+func checkVersion(name, current, required string) (RequirementCheck, error) {
+	requiredVersion, err := semver.ParseTolerant(required)
+	if err != nil {
+		return RequirementCheck{}, errors.Wrapf(err, "invalid required version %s", required)
+	}
+
+	check := RequirementCheck{Name: name}
+	currentVersion, err := semver.ParseTolerant(current)
+	switch {
+	case err != nil:
+		check.Message = fmt.Sprintf("unknown version %s, %s is required", current, required)
+	case currentVersion.LT(requiredVersion):
+		check.Message = fmt.Sprintf("version %s, %s is required", current, required)
+	default:
+		check.OK = true
+		check.Message = "version " + current
+	}
+
+	return check, nil
+}
+
+func checkLicense(requirement LicenseRequirement, config *model.Config, license *model.License) RequirementCheck {
+	check := RequirementCheck{Name: "License"}
+
+	switch requirement {
+	case LicenseE10:
+		check.OK = IsE10LicensedOrDevelopment(config, license)
+		check.Message = "a Professional or E10 license is required"
+	case LicenseE20:
+		check.OK = IsE20LicensedOrDevelopment(config, license)
+		check.Message = "an Enterprise or E20 license is required"
+	default:
+		check.OK = IsEnterpriseLicensedOrDevelopment(config, license)
+		check.Message = "a license is required"
+	}
+
+	if check.OK {
+		check.Message = ""
+		if license != nil {
+			check.Message = license.SkuShortName
+		}
+	}
+
+	return check
+}
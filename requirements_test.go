@@ -0,0 +1,108 @@
+package pluginapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	"github.com/mattermost/mattermost-server/v5/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestCheckRequirements(t *testing.T) {
+	setup := func() *plugintest.API {
+		config := &model.Config{}
+		config.SetDefaults()
+		config.PluginSettings.PluginStates["jira"] = &model.PluginState{Enable: true}
+		config.PluginSettings.PluginStates["github"] = &model.PluginState{Enable: false}
+		config.PluginSettings.PluginStates["zoom"] = &model.PluginState{Enable: true}
+
+		api := &plugintest.API{}
+		api.On("GetServerVersion").Return("5.30.1")
+		api.On("GetConfig").Return(config)
+		api.On("GetLicense").Return(&model.License{SkuShortName: "professional"})
+		api.On("GetPluginStatus", "jira").Return(&model.PluginStatus{PluginId: "jira", State: model.PluginStateRunning, Version: "3.0.0"}, nil)
+		api.On("GetPluginStatus", "github").Return(&model.PluginStatus{PluginId: "github", State: model.PluginStateNotRunning, Version: "2.0.0"}, nil)
+		api.On("GetPluginStatus", "zoom").Return(&model.PluginStatus{PluginId: "zoom", State: model.PluginStateFailedToStart, Version: "1.0.0"}, nil)
+		api.On("GetPluginStatus", "todo").Return(nil, &model.AppError{StatusCode: http.StatusNotFound})
+		return api
+	}
+
+	t.Run("all met", func(t *testing.T) {
+		client := pluginapi.NewClient(setup(), &plugintest.Driver{})
+
+		report, err := client.Plugin.CheckRequirements(pluginapi.Requirements{
+			MinServerVersion: "5.30.0",
+			License:          pluginapi.LicenseE10,
+			Configuration: &model.Config{ServiceSettings: model.ServiceSettings{
+				EnableCommands: model.NewBool(true),
+			}},
+			Plugins: []pluginapi.PluginRequirement{
+				{ID: "jira", MinVersion: "v3.0.0"},
+				{ID: "todo", Optional: true},
+			},
+		})
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+		assert.NoError(t, report.Err())
+		assert.Empty(t, report.Failures())
+		assert.Equal(t, ""+
+			"- **Server version**: OK (version 5.30.1)\n"+
+			"- **License**: OK (professional)\n"+
+			"- **Server configuration**: OK\n"+
+			"- **Plugin jira**: OK (version 3.0.0)\n"+
+			"- **Plugin todo**: Warning (not installed)",
+			report.String(),
+		)
+	})
+
+	t.Run("not met", func(t *testing.T) {
+		client := pluginapi.NewClient(setup(), &plugintest.Driver{})
+
+		report, err := client.Plugin.CheckRequirements(pluginapi.Requirements{
+			MinServerVersion: "5.31.0",
+			License:          pluginapi.LicenseE20,
+			Configuration: &model.Config{ServiceSettings: model.ServiceSettings{
+				EnableCommands: model.NewBool(false),
+			}},
+			Plugins: []pluginapi.PluginRequirement{
+				{ID: "jira", MinVersion: "3.1.0"},
+				{ID: "github"},
+				{ID: "zoom"},
+				{ID: "todo"},
+			},
+		})
+		require.NoError(t, err)
+		assert.False(t, report.OK())
+		require.Len(t, report.Failures(), 7)
+		assert.Equal(t, ""+
+			"- **Server version**: Failed (version 5.30.1, 5.31.0 is required)\n"+
+			"- **License**: Failed (an Enterprise or E20 license is required)\n"+
+			"- **Server configuration**: Failed (the server is not configured as required)\n"+
+			"- **Plugin jira**: Failed (version 3.0.0, 3.1.0 is required)\n"+
+			"- **Plugin github**: Failed (installed but disabled)\n"+
+			"- **Plugin zoom**: Failed (enabled but not running)\n"+
+			"- **Plugin todo**: Failed (not installed)",
+			report.String(),
+		)
+		assert.EqualError(t, report.Err(), "requirements not met: "+
+			"server version: version 5.30.1, 5.31.0 is required; "+
+			"license: an Enterprise or E20 license is required; "+
+			"server configuration: the server is not configured as required; "+
+			"plugin jira: version 3.0.0, 3.1.0 is required; "+
+			"plugin github: installed but disabled; "+
+			"plugin zoom: enabled but not running; "+
+			"plugin todo: not installed",
+		)
+	})
+
+	t.Run("invalid requirement", func(t *testing.T) {
+		client := pluginapi.NewClient(setup(), &plugintest.Driver{})
+
+		_, err := client.Plugin.CheckRequirements(pluginapi.Requirements{MinServerVersion: "latest"})
+		assert.Error(t, err)
+	})
+}